@@ -0,0 +1,230 @@
+package ivy
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// bindSources are the struct tags, that Bind reads values from
+var bindSources = []string{"path", "query", "header", "cookie", "form"}
+
+// Bind reads values from the request into struct pointed to by v, as per the struct tags on its fields
+//
+//   - `path:"id"` reads path param
+//   - `query:"page"` reads query param
+//   - `header:"X-Tenant"` reads request header
+//   - `cookie:"sid"` reads cookie value
+//   - `form:"name"` reads form value of request body (both url-encoded and multipart forms), not of URL query
+//   - `default:"10"` is used when value is missing in request, for slices values are comma separated
+//
+// Fields can be of string, bool, int, uint, float kinds, [time.Duration], [encoding.TextUnmarshaler] (like [time.Time]), or pointers and slices of those.
+// Failures for every field are collected into a single [HTTPError] with status code 400, with a [FieldError] for each of them.
+// Once bound, v is validated with [Validate].
+//
+// Example:
+//
+//	var in struct {
+//	    ID     int           `path:"id"`
+//	    Page   int           `query:"page" default:"1"`
+//	    Tags   []string      `query:"tag"`
+//	    Tenant string        `header:"X-Tenant"`
+//	    Wait   time.Duration `query:"wait"`
+//	}
+//	if err := c.Bind(&in); err != nil {
+//	    return err
+//	}
+func (c *Context) Bind(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("ivy: Bind requires a pointer to struct, got %T", v)
+	}
+
+	if err := c.bind(rv, false); err != nil {
+		return err
+	}
+
+	return Validate(v)
+}
+
+// bind is Bind without validation, rv must be a pointer to struct. bodyDecoded is set, when request body has already been decoded into rv
+func (c *Context) bind(rv reflect.Value, bodyDecoded bool) error {
+	b := &binder{request: c.request, bodyDecoded: bodyDecoded}
+
+	var errs []error
+	b.bindStruct(rv.Elem(), &errs)
+	if len(errs) > 0 {
+		return NewHTTPErrors(http.StatusBadRequest, "invalid request", errs...)
+	}
+
+	return nil
+}
+
+type binder struct {
+	request *http.Request
+	query   url.Values
+	form    url.Values
+
+	// bodyDecoded skips form values, as the body they come from has already been decoded,
+	// along with defaults of fields, that the body has set
+	bodyDecoded bool
+}
+
+func (b *binder) bindStruct(v reflect.Value, errs *[]error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fv := v.Field(i)
+
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct && !hasBindTag(sf) {
+			b.bindStruct(fv, errs)
+			continue
+		}
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		source, name := bindTag(sf)
+		if source == "" {
+			continue
+		}
+
+		// binder without a request, only has form values, e.g. when decoding form bodies with FormCodec
+		if b.request == nil && source != "form" {
+			continue
+		}
+		if b.bodyDecoded && source == "form" {
+			continue
+		}
+
+		values, err := b.lookup(source, name)
+		if err != nil {
+			*errs = append(*errs, &FieldError{Field: name, Source: source, Err: err})
+			continue
+		}
+
+		if len(values) == 0 {
+			d, ok := sf.Tag.Lookup("default")
+			if !ok || b.bodyDecoded && !fv.IsZero() {
+				continue
+			}
+
+			values = []string{d}
+			if isSliceField(sf.Type) {
+				values = strings.Split(d, ",")
+			}
+		}
+
+		if err := setField(fv, values); err != nil {
+			*errs = append(*errs, &FieldError{Field: name, Source: source, Err: err})
+		}
+	}
+}
+
+func hasBindTag(sf reflect.StructField) bool {
+	source, _ := bindTag(sf)
+	return source != ""
+}
+
+// bindTag returns the first bind source tag on the field, along with the name in it
+func bindTag(sf reflect.StructField) (source string, name string) {
+	for _, source := range bindSources {
+		tag, ok := sf.Tag.Lookup(source)
+		if !ok {
+			continue
+		}
+
+		name, _, _ = strings.Cut(tag, ",")
+		if name == "-" {
+			return "", ""
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		return source, name
+	}
+
+	return "", ""
+}
+
+func (b *binder) lookup(source string, name string) ([]string, error) {
+	switch source {
+	case "path":
+		if v := b.request.PathValue(name); v != "" {
+			return []string{v}, nil
+		}
+		return nil, nil
+	case "query":
+		if b.query == nil {
+			b.query = b.request.URL.Query()
+		}
+		return b.query[name], nil
+	case "header":
+		return b.request.Header.Values(name), nil
+	case "cookie":
+		cookie, err := b.request.Cookie(name)
+		if err != nil {
+			return nil, nil
+		}
+		return []string{cookie.Value}, nil
+	case "form":
+		if b.form == nil {
+			if err := b.parseForm(); err != nil {
+				return nil, err
+			}
+		}
+		return b.form[name], nil
+	}
+
+	return nil, nil
+}
+
+func (b *binder) parseForm() error {
+	if strings.HasPrefix(b.request.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := b.request.ParseMultipartForm(32 << 20); err != nil {
+			return err
+		}
+	} else if err := b.request.ParseForm(); err != nil {
+		return err
+	}
+
+	// INFO: request.Form has URL query values as well, which are only bound with `query` tags.
+	// PostForm has values of both url-encoded and multipart bodies
+	b.form = b.request.PostForm
+	return nil
+}
+
+func isSliceField(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Slice && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// setField parses values into field v, allocating pointers and slices as needed
+func setField(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		if err := setField(ptr.Elem(), values); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+
+	if v.Kind() == reflect.Slice && !reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i := range values {
+			if err := setField(slice.Index(i), values[i:i+1]); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+
+	return parseValue(values[0], v)
+}
@@ -0,0 +1,224 @@
+package ivy
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Codec encodes and decodes values of a media type
+type Codec interface {
+	Encode(w io.Writer, v any) error
+	Decode(r io.Reader, v any) error
+}
+
+// Codecs is a registry of codecs keyed by their media type.
+// It is used by [Context.Send] to pick an encoder as per request's `Accept` header,
+// and by [Context.ParseBodyInto] to pick a decoder as per request's `Content-Type` header
+//
+// Codecs are set per router, with [Router.Codecs]
+type Codecs struct {
+	mediaTypes []string
+	codecs     map[string]Codec
+}
+
+// Media types of the builtin codecs
+const (
+	MediaTypeJSON   = "application/json"
+	MediaTypeXML    = "application/xml"
+	MediaTypeForm   = "application/x-www-form-urlencoded"
+	MediaTypeNDJSON = "application/x-ndjson"
+)
+
+// NewCodecs creates a codec registry, with JSON, XML, form-urlencoded and NDJSON codecs registered.
+// JSON is the first registered codec, so it is used when a request accepts anything, or does not specify its content type
+func NewCodecs() *Codecs {
+	cs := &Codecs{codecs: make(map[string]Codec, 4)}
+	cs.Register(MediaTypeJSON, JSONCodec{})
+	cs.Register(MediaTypeXML, XMLCodec{})
+	cs.Register(MediaTypeForm, FormCodec{})
+	cs.Register(MediaTypeNDJSON, NDJSONCodec{})
+	return cs
+}
+
+// defaultCodecs are used, when router (or its parents) have no codecs set
+var defaultCodecs = NewCodecs()
+
+// Register adds (or replaces) codec for mediaType
+func (cs *Codecs) Register(mediaType string, codec Codec) {
+	mediaType = strings.ToLower(mediaType)
+	if _, ok := cs.codecs[mediaType]; !ok {
+		cs.mediaTypes = append(cs.mediaTypes, mediaType)
+	}
+	cs.codecs[mediaType] = codec
+}
+
+// Remove removes codec for mediaType
+func (cs *Codecs) Remove(mediaType string) {
+	mediaType = strings.ToLower(mediaType)
+	delete(cs.codecs, mediaType)
+	cs.mediaTypes = slices.DeleteFunc(cs.mediaTypes, func(mt string) bool { return mt == mediaType })
+}
+
+// Lookup finds codec for a Content-Type header value, media type parameters like charset are ignored
+func (cs *Codecs) Lookup(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	codec, ok := cs.codecs[mediaType]
+	return codec, ok
+}
+
+// Negotiate picks the codec for an Accept header value, as per its q-values.
+// An empty Accept header accepts anything, and media ranges with q=0 exclude the media types they match (RFC 9110 §12.5.1),
+// like `application/json;q=0, */*` accepting anything but JSON.
+func (cs *Codecs) Negotiate(accept string) (string, Codec, bool) {
+	if len(cs.mediaTypes) == 0 {
+		return "", nil, false
+	}
+
+	if strings.TrimSpace(accept) == "" {
+		return cs.mediaTypes[0], cs.codecs[cs.mediaTypes[0]], true
+	}
+
+	ranges := parseAccept(accept)
+
+	var best string
+	var bestRange mediaRange
+	for _, mediaType := range cs.mediaTypes {
+		mr, ok := mostSpecificMatch(ranges, mediaType)
+		if !ok || mr.q <= 0 {
+			continue
+		}
+
+		// INFO: ties are broken by specificity of the matching range, and then by order of codecs
+		if best == "" || mr.q > bestRange.q || (mr.q == bestRange.q && mr.specificity() > bestRange.specificity()) {
+			best, bestRange = mediaType, mr
+		}
+	}
+
+	if best == "" {
+		return "", nil, false
+	}
+	return best, cs.codecs[best], true
+}
+
+// mostSpecificMatch finds the most specific media range matching mediaType, its q-value is the one that applies to mediaType
+func mostSpecificMatch(ranges []mediaRange, mediaType string) (mediaRange, bool) {
+	var match mediaRange
+	found := false
+	for _, mr := range ranges {
+		if mr.matches(mediaType) && (!found || mr.specificity() > match.specificity()) {
+			match, found = mr, true
+		}
+	}
+	return match, found
+}
+
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+func (mr mediaRange) matches(mediaType string) bool {
+	if mr.mediaType == "*/*" || mr.mediaType == mediaType {
+		return true
+	}
+
+	if prefix, ok := strings.CutSuffix(mr.mediaType, "/*"); ok {
+		return strings.HasPrefix(mediaType, prefix+"/")
+	}
+
+	return false
+}
+
+// specificity ranks exact media types above `type/*` and `*/*`
+func (mr mediaRange) specificity() int {
+	switch {
+	case mr.mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(mr.mediaType, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// parseAccept parses Accept header into media ranges, including the ones with q=0, which exclude media types
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		if mediaType == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(k, "q") {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+
+		ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+	}
+
+	return ranges
+}
+
+// codecs returns the nearest codecs, looking up through parent groups and mount points
+func (r *Router) codecs() *Codecs {
+	for router := r; router != nil; {
+		if router.Codecs != nil {
+			return router.Codecs
+		}
+
+		switch {
+		case router.parent != nil:
+			router = router.parent
+		case router.mountedAt != nil:
+			router = router.mountedAt.parent
+		default:
+			router = nil
+		}
+	}
+
+	return defaultCodecs
+}
+
+func (c *Context) codecs() *Codecs {
+	if c.router == nil {
+		return defaultCodecs
+	}
+	return c.router.codecs()
+}
+
+// Send encodes v with the codec negotiated from request's `Accept` header, and sets `Content-Type` accordingly.
+// When no codec is acceptable, it returns an [HTTPError] with status code 406
+func (c *Context) Send(v any) error {
+	return c.sendWithStatus(0, v)
+}
+
+// sendWithStatus is like Send, but also writes status code (if non zero) once Content-Type is set
+func (c *Context) sendWithStatus(code int, v any) error {
+	mediaType, codec, ok := c.codecs().Negotiate(c.request.Header.Get("Accept"))
+	if !ok {
+		return NewHTTPError(http.StatusNotAcceptable, fmt.Sprintf("none of the accepted media types (%s) is supported", c.request.Header.Get("Accept")))
+	}
+
+	c.response.Header().Set("Content-Type", mediaType)
+	if code != 0 {
+		c.response.WriteHeader(code)
+	}
+	return codec.Encode(c.response, v)
+}
@@ -0,0 +1,371 @@
+// Package cbor provides a dependency free [CBOR](https://www.rfc-editor.org/rfc/rfc8949) codec for ivy.
+//
+// Values are encoded like encoding/json would (honouring `json` struct tags, omitempty, json.Marshaler and encoding.TextMarshaler),
+// except []byte, which is carried as CBOR byte string. Decoding into `any` gives int64 (or uint64) for integers, and []byte for byte strings.
+// While decoding, tags are skipped over, and their content is used as is.
+//
+// Example:
+//
+//	r.Codecs = ivy.NewCodecs()
+//	r.Codecs.Register(cbor.MediaType, cbor.Codec{})
+package cbor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/nxtcoder17/ivy"
+	"github.com/nxtcoder17/ivy/codec/internal/generic"
+)
+
+// MediaType of CBOR bodies
+const MediaType = "application/cbor"
+
+// Codec implements [ivy.Codec] for CBOR
+type Codec struct{}
+
+var _ ivy.Codec = Codec{}
+
+// Encode implements ivy.Codec.
+func (Codec) Encode(w io.Writer, v any) error {
+	bw := bufio.NewWriter(w)
+	if err := generic.Encode(writer{w: bw}, v); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Decode implements ivy.Codec.
+func (Codec) Decode(r io.Reader, v any) error {
+	g, err := decode(bufio.NewReader(r), 0)
+	if err != nil {
+		return err
+	}
+	return generic.Into(g, v)
+}
+
+// Marshal encodes v as CBOR
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := (Codec{}).Encode(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes CBOR data into v
+func Unmarshal(data []byte, v any) error {
+	return Codec{}.Decode(bytes.NewReader(data), v)
+}
+
+// major types
+const (
+	majorUint   byte = 0
+	majorNegInt byte = 1
+	majorBytes  byte = 2
+	majorText   byte = 3
+	majorArray  byte = 4
+	majorMap    byte = 5
+	majorTag    byte = 6
+	majorSimple byte = 7
+)
+
+// writer writes CBOR, it implements generic.Writer
+type writer struct {
+	w *bufio.Writer
+}
+
+var _ generic.Writer = writer{}
+
+func (w writer) Nil() error {
+	return w.w.WriteByte(0xf6)
+}
+
+func (w writer) Bool(b bool) error {
+	if b {
+		return w.w.WriteByte(0xf5)
+	}
+	return w.w.WriteByte(0xf4)
+}
+
+func (w writer) Int(n int64) error {
+	if n >= 0 {
+		return writeHead(w.w, majorUint, uint64(n))
+	}
+	return writeHead(w.w, majorNegInt, uint64(-(n + 1)))
+}
+
+func (w writer) Uint(n uint64) error {
+	return writeHead(w.w, majorUint, n)
+}
+
+func (w writer) Float(f float64, bits int) error {
+	var buf [9]byte
+	if bits == 32 {
+		buf[0] = 0xfa
+		binary.BigEndian.PutUint32(buf[1:], math.Float32bits(float32(f)))
+		_, err := w.w.Write(buf[:5])
+		return err
+	}
+	buf[0] = 0xfb
+	binary.BigEndian.PutUint64(buf[1:], math.Float64bits(f))
+	_, err := w.w.Write(buf[:])
+	return err
+}
+
+func (w writer) String(s string) error {
+	if err := writeHead(w.w, majorText, uint64(len(s))); err != nil {
+		return err
+	}
+	_, err := w.w.WriteString(s)
+	return err
+}
+
+func (w writer) Bytes(b []byte) error {
+	if err := writeHead(w.w, majorBytes, uint64(len(b))); err != nil {
+		return err
+	}
+	_, err := w.w.Write(b)
+	return err
+}
+
+func (w writer) Array(n int) error {
+	return writeHead(w.w, majorArray, uint64(n))
+}
+
+func (w writer) Map(n int) error {
+	return writeHead(w.w, majorMap, uint64(n))
+}
+
+// writeHead writes initial byte for major type, along with argument n in its shortest form
+func writeHead(w *bufio.Writer, major byte, n uint64) error {
+	var buf [9]byte
+	switch {
+	case n < 24:
+		return w.WriteByte(major<<5 | byte(n))
+	case n <= math.MaxUint8:
+		buf[0], buf[1] = major<<5|24, byte(n)
+		_, err := w.Write(buf[:2])
+		return err
+	case n <= math.MaxUint16:
+		buf[0] = major<<5 | 25
+		binary.BigEndian.PutUint16(buf[1:], uint16(n))
+		_, err := w.Write(buf[:3])
+		return err
+	case n <= math.MaxUint32:
+		buf[0] = major<<5 | 26
+		binary.BigEndian.PutUint32(buf[1:], uint32(n))
+		_, err := w.Write(buf[:5])
+		return err
+	default:
+		buf[0] = major<<5 | 27
+		binary.BigEndian.PutUint64(buf[1:], n)
+		_, err := w.Write(buf[:9])
+		return err
+	}
+}
+
+var (
+	errUnexpectedEOF = errors.New("cbor: unexpected end of data")
+
+	// errBreak is returned when the `break` stop code of indefinite length items is read
+	errBreak = errors.New("cbor: unexpected break")
+)
+
+// infoIndefinite is the additional info, for items of indefinite length
+const infoIndefinite byte = 31
+
+func readHead(r *bufio.Reader) (major byte, info byte, n uint64, err error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, 0, 0, errUnexpectedEOF
+	}
+
+	major, info = b>>5, b&0x1f
+
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		size := 1 << (info - 24)
+		var buf [8]byte
+		if _, err := io.ReadFull(r, buf[8-size:]); err != nil {
+			return 0, 0, 0, errUnexpectedEOF
+		}
+		return major, info, binary.BigEndian.Uint64(buf[:]), nil
+	case info == infoIndefinite && major != majorUint && major != majorNegInt && major != majorTag:
+		return major, info, 0, nil
+	}
+
+	return 0, 0, 0, fmt.Errorf("cbor: malformed initial byte 0x%02x", b)
+}
+
+// maxDepth is how deep arrays, maps and tags can be nested, like in encoding/json, so that hostile input can not overflow the stack
+const maxDepth = 10000
+
+var errTooDeep = fmt.Errorf("cbor: exceeded max depth of %d", maxDepth)
+
+// decode decodes a data item, that is nested in depth arrays, maps and tags
+func decode(r *bufio.Reader, depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errTooDeep
+	}
+
+	major, info, n, err := readHead(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case majorUint:
+		if n <= math.MaxInt64 {
+			return int64(n), nil
+		}
+		return n, nil
+	case majorNegInt:
+		if n > math.MaxInt64 {
+			return nil, fmt.Errorf("cbor: negative integer overflows int64")
+		}
+		return -1 - int64(n), nil
+	case majorBytes, majorText:
+		b, err := readString(r, major, info, n)
+		if err != nil {
+			return nil, err
+		}
+		if major == majorText {
+			return string(b), nil
+		}
+		return b, nil
+	case majorArray:
+		arr := make([]any, 0, min(n, 1024))
+		for i := uint64(0); info == infoIndefinite || i < n; i++ {
+			v, err := decode(r, depth+1)
+			if errors.Is(err, errBreak) && info == infoIndefinite {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+	case majorMap:
+		m := make(map[string]any, min(n, 1024))
+		for i := uint64(0); info == infoIndefinite || i < n; i++ {
+			k, err := decode(r, depth+1)
+			if errors.Is(err, errBreak) && info == infoIndefinite {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			v, err := decode(r, depth+1)
+			if err != nil {
+				return nil, err
+			}
+
+			key, ok := k.(string)
+			if !ok {
+				key = fmt.Sprint(k)
+			}
+			m[key] = v
+		}
+		return m, nil
+	case majorTag:
+		return decode(r, depth+1)
+	case majorSimple:
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		case 25:
+			return float16(uint16(n)), nil
+		case 26:
+			return float64(math.Float32frombits(uint32(n))), nil
+		case 27:
+			return math.Float64frombits(n), nil
+		case infoIndefinite:
+			return nil, errBreak
+		}
+		return nil, fmt.Errorf("cbor: unsupported simple value %d", n)
+	}
+
+	return nil, fmt.Errorf("cbor: unsupported major type %d", major)
+}
+
+// readString reads byte or text string of length n, for indefinite length, it reads and concatenates all the chunks
+func readString(r *bufio.Reader, major byte, info byte, n uint64) ([]byte, error) {
+	if info != infoIndefinite {
+		return readBytes(r, n)
+	}
+
+	var buf []byte
+	for {
+		chunkMajor, chunkInfo, chunkLen, err := readHead(r)
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor == majorSimple && chunkInfo == infoIndefinite {
+			return buf, nil
+		}
+		if chunkMajor != major || chunkInfo == infoIndefinite {
+			return nil, fmt.Errorf("cbor: malformed indefinite length string")
+		}
+
+		chunk, err := readBytes(r, chunkLen)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, chunk...)
+	}
+}
+
+func readBytes(r *bufio.Reader, n uint64) ([]byte, error) {
+	if n > math.MaxInt32 {
+		return nil, fmt.Errorf("cbor: length %d is too large", n)
+	}
+	// INFO: reading in chunks, so that a bogus length does not allocate a huge buffer upfront
+	buf := make([]byte, 0, min(n, 4096))
+	for uint64(len(buf)) < n {
+		chunk := min(n-uint64(len(buf)), 4096)
+		start := len(buf)
+		buf = append(buf, make([]byte, chunk)...)
+		if _, err := io.ReadFull(r, buf[start:]); err != nil {
+			return nil, errUnexpectedEOF
+		}
+	}
+	return buf, nil
+}
+
+// float16 converts IEEE 754 half precision float to float64
+func float16(h uint16) float64 {
+	exp := (h >> 10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	var v float64
+	switch exp {
+	case 0:
+		v = mant * math.Pow(2, -24)
+	case 31:
+		if mant == 0 {
+			v = math.Inf(1)
+		} else {
+			v = math.NaN()
+		}
+	default:
+		v = (mant + 1024) * math.Pow(2, float64(exp)-25)
+	}
+
+	if h&0x8000 != 0 {
+		return -v
+	}
+	return v
+}
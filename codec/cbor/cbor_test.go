@@ -0,0 +1,120 @@
+package cbor
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type sample struct {
+	Data   []byte            `json:"data"`
+	Name   string            `json:"name"`
+	Count  int64             `json:"count"`
+	Neg    int               `json:"neg"`
+	Ratio  float64           `json:"ratio"`
+	OK     bool              `json:"ok"`
+	Tags   []string          `json:"tags"`
+	Labels map[string]string `json:"labels"`
+	Empty  *string           `json:"empty"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := sample{
+		Data:   []byte{0, 1, 0xff},
+		Name:   "ivy",
+		Count:  1 << 40,
+		Neg:    -200,
+		Ratio:  0.25,
+		OK:     true,
+		Tags:   []string{"a", "b"},
+		Labels: map[string]string{"env": "dev"},
+	}
+
+	b, err := Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out sample
+	if err := Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip:\n\t got: %+v\n\twant: %+v", out, in)
+	}
+}
+
+func TestDecodeRFCExamples(t *testing.T) {
+	// examples from RFC 8949, Appendix A
+	tests := []struct {
+		in   []byte
+		want any
+	}{
+		{in: []byte{0x18, 0x64}, want: int64(100)},
+		{in: []byte{0x38, 0x63}, want: int64(-100)},
+		{in: []byte{0xf9, 0x3e, 0x00}, want: 1.5},
+		{in: []byte{0x44, 0x01, 0x02, 0x03, 0x04}, want: []byte{1, 2, 3, 4}},
+		{in: []byte{0x7f, 0x65, 's', 't', 'r', 'e', 'a', 0x64, 'm', 'i', 'n', 'g', 0xff}, want: "streaming"},
+		{in: []byte{0x9f, 0x01, 0x82, 0x02, 0x03, 0xff}, want: []any{int64(1), []any{int64(2), int64(3)}}},
+		{in: []byte{0xbf, 0x61, 'a', 0x01, 0xff}, want: map[string]any{"a": int64(1)}},
+		{in: []byte{0xc1, 0x1a, 0x51, 0x4b, 0x67, 0xb0}, want: int64(1363896240)},
+	}
+
+	for _, tt := range tests {
+		var got any
+		if err := Unmarshal(tt.in, &got); err != nil {
+			t.Fatalf("Unmarshal(% x): %v", tt.in, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Unmarshal(% x): got %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	got, err := Marshal(map[string]any{"a": 1, "b": []int{2, 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []byte{0xa2, 0x61, 'a', 0x01, 0x61, 'b', 0x82, 0x02, 0x03}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Marshal: got % x, want % x", got, want)
+	}
+}
+
+func TestEncoding_Bytes(t *testing.T) {
+	got, err := Marshal(struct {
+		Data []byte `json:"data"`
+	}{Data: []byte{1, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// []byte is a byte string (major type 2), not base64 text
+	want := []byte{0xa1, 0x64, 'd', 'a', 't', 'a', 0x42, 0x01, 0x02}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Marshal: got % x, want % x", got, want)
+	}
+}
+
+func TestDecodeDeeplyNested(t *testing.T) {
+	// nested arrays (and maps) must fail with an error, instead of overflowing the stack
+	for _, prefix := range []byte{0x81, 0xa1} {
+		data := bytes.Repeat([]byte{prefix}, 5<<20)
+
+		var v any
+		if err := Unmarshal(data, &v); err == nil || !strings.Contains(err.Error(), "max depth") {
+			t.Errorf("nested 0x%02x: got error %v", prefix, err)
+		}
+	}
+
+	// nesting up to the limit is fine
+	data := append(bytes.Repeat([]byte{0x81}, maxDepth), 0x01)
+	var v any
+	if err := Unmarshal(data, &v); err != nil {
+		t.Errorf("nested up to max depth: %v", err)
+	}
+}
@@ -0,0 +1,702 @@
+// Package generic walks Go values for binary codecs, honouring `json` struct tags the way encoding/json does,
+// so that the same types can be sent as JSON, MessagePack or CBOR.
+//
+// Codecs implement [Writer] to encode values, and decode their format into generic values
+// (nil, bool, int64, uint64, float64, string, []byte, []any and map[string]any), which [Into] stores into Go values.
+package generic
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Writer writes values in a binary format
+type Writer interface {
+	Nil() error
+	Bool(b bool) error
+	Int(n int64) error
+	Uint(n uint64) error
+	// Float writes f, with bits being 32 for float32 values, and 64 otherwise
+	Float(f float64, bits int) error
+	String(s string) error
+	Bytes(b []byte) error
+	// Array writes header of an array, that is followed by n values
+	Array(n int) error
+	// Map writes header of a map, that is followed by n keys and values
+	Map(n int) error
+}
+
+var (
+	jsonMarshalerType   = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	jsonNumberType      = reflect.TypeFor[json.Number]()
+)
+
+// Encode writes v to w
+func Encode(w Writer, v any) error {
+	return encodeValue(w, reflect.ValueOf(v))
+}
+
+func encodeValue(w Writer, rv reflect.Value) error {
+	if !rv.IsValid() {
+		return w.Nil()
+	}
+
+	if rv.Type() == jsonNumberType {
+		return encodeNumber(w, json.Number(rv.String()))
+	}
+
+	if m, ok := marshaler(rv, jsonMarshalerType); ok {
+		if m == nil {
+			return w.Nil()
+		}
+		// INFO: custom JSON representations are kept, by writing the value they decode into
+		b, err := m.(json.Marshaler).MarshalJSON()
+		if err != nil {
+			return err
+		}
+		g, err := decodeJSON(b)
+		if err != nil {
+			return err
+		}
+		return Encode(w, g)
+	}
+
+	if m, ok := marshaler(rv, textMarshalerType); ok {
+		if m == nil {
+			return w.Nil()
+		}
+		b, err := m.(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+		return w.String(string(b))
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return w.Bool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return w.Int(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return w.Uint(rv.Uint())
+	case reflect.Float32:
+		return w.Float(rv.Float(), 32)
+	case reflect.Float64:
+		return w.Float(rv.Float(), 64)
+	case reflect.String:
+		return w.String(rv.String())
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return w.Nil()
+		}
+		return encodeValue(w, rv.Elem())
+	case reflect.Slice:
+		if rv.IsNil() {
+			return w.Nil()
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return w.Bytes(rv.Bytes())
+		}
+		return encodeArray(w, rv)
+	case reflect.Array:
+		return encodeArray(w, rv)
+	case reflect.Map:
+		if rv.IsNil() {
+			return w.Nil()
+		}
+		return encodeMap(w, rv)
+	case reflect.Struct:
+		return encodeStruct(w, rv)
+	}
+
+	return fmt.Errorf("unsupported type %s", rv.Type())
+}
+
+// marshaler returns rv (or its address) as a value implementing iface. It is nil for nil pointers, that implement it
+func marshaler(rv reflect.Value, iface reflect.Type) (any, bool) {
+	if rv.Type().Implements(iface) {
+		if (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface) && rv.IsNil() {
+			return nil, true
+		}
+		return rv.Interface(), true
+	}
+	if rv.Kind() != reflect.Pointer && rv.CanAddr() && reflect.PointerTo(rv.Type()).Implements(iface) {
+		return rv.Addr().Interface(), true
+	}
+	return nil, false
+}
+
+func encodeNumber(w Writer, n json.Number) error {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return w.Int(i)
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return w.Uint(u)
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return err
+	}
+	return w.Float(f, 64)
+}
+
+func decodeJSON(b []byte) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(string(b)))
+	dec.UseNumber()
+
+	var g any
+	if err := dec.Decode(&g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func encodeArray(w Writer, rv reflect.Value) error {
+	if err := w.Array(rv.Len()); err != nil {
+		return err
+	}
+	for i := range rv.Len() {
+		if err := encodeValue(w, rv.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeMap(w Writer, rv reflect.Value) error {
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+
+	entries := make([]entry, 0, rv.Len())
+	for iter := rv.MapRange(); iter.Next(); {
+		key, err := mapKey(iter.Key())
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{key: key, value: iter.Value()})
+	}
+	// INFO: sorted like encoding/json, so that encoding is deterministic
+	slices.SortFunc(entries, func(a, b entry) int { return strings.Compare(a.key, b.key) })
+
+	if err := w.Map(len(entries)); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := w.String(e.key); err != nil {
+			return err
+		}
+		if err := encodeValue(w, e.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mapKey converts a map key to string, like encoding/json does
+func mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if m, ok := marshaler(k, textMarshalerType); ok && m != nil {
+		b, err := m.(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("unsupported map key type %s", k.Type())
+}
+
+func encodeStruct(w Writer, rv reflect.Value) error {
+	fields := cachedFields(rv.Type())
+
+	values := make([]reflect.Value, 0, len(fields))
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		fv, ok := fieldByIndex(rv, f.index, false)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+		values = append(values, fv)
+		names = append(names, f.name)
+	}
+
+	if err := w.Map(len(values)); err != nil {
+		return err
+	}
+	for i := range values {
+		if err := w.String(names[i]); err != nil {
+			return err
+		}
+		if err := encodeValue(w, values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex, that allocates nil embedded pointers when alloc is set, and otherwise reports them as missing
+func fieldByIndex(rv reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				if !alloc || !rv.CanSet() {
+					return reflect.Value{}, false
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+
+	// tagged is set, when name comes from the `json` tag
+	tagged bool
+}
+
+var fieldCache sync.Map // map[reflect.Type][]field
+
+func cachedFields(t reflect.Type) []field {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]field)
+	}
+	f, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return f.([]field)
+}
+
+// typeFields lists fields of struct type t, as encoding/json sees them: fields of embedded structs are promoted,
+// and among fields with the same name, the least nested (or else the only tagged) one wins
+func typeFields(t reflect.Type) []field {
+	var all []field
+	collectFields(t, nil, map[reflect.Type]bool{}, &all)
+
+	byName := map[string][]field{}
+	var order []string
+	for _, f := range all {
+		if _, ok := byName[f.name]; !ok {
+			order = append(order, f.name)
+		}
+		byName[f.name] = append(byName[f.name], f)
+	}
+
+	fields := make([]field, 0, len(order))
+	for _, name := range order {
+		if f, ok := dominantField(byName[name]); ok {
+			fields = append(fields, f)
+		}
+	}
+
+	slices.SortStableFunc(fields, func(a, b field) int { return slices.Compare(a.index, b.index) })
+	return fields
+}
+
+func collectFields(t reflect.Type, index []int, visited map[reflect.Type]bool, fields *[]field) {
+	if visited[t] {
+		return
+	}
+	visited[t] = true
+	defer delete(visited, t)
+
+	for i := range t.NumField() {
+		sf := t.Field(i)
+
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		if sf.Anonymous {
+			if !sf.IsExported() && ft.Kind() != reflect.Struct {
+				continue
+			}
+			if name == "" && ft.Kind() == reflect.Struct {
+				collectFields(ft, append(slices.Clone(index), i), visited, fields)
+				continue
+			}
+		} else if !sf.IsExported() {
+			continue
+		}
+
+		f := field{name: name, index: append(slices.Clone(index), i), tagged: name != ""}
+		if f.name == "" {
+			f.name = sf.Name
+		}
+		for _, opt := range strings.Split(opts, ",") {
+			if opt == "omitempty" {
+				f.omitEmpty = true
+			}
+		}
+		*fields = append(*fields, f)
+	}
+}
+
+func dominantField(fields []field) (field, bool) {
+	depth := len(fields[0].index)
+	for _, f := range fields[1:] {
+		depth = min(depth, len(f.index))
+	}
+
+	var candidates []field
+	for _, f := range fields {
+		if len(f.index) == depth {
+			candidates = append(candidates, f)
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0], true
+	}
+
+	var tagged []field
+	for _, f := range candidates {
+		if f.tagged {
+			tagged = append(tagged, f)
+		}
+	}
+	if len(tagged) == 1 {
+		return tagged[0], true
+	}
+	return field{}, false
+}
+
+// Into stores generic value g into v, which must be a non-nil pointer
+func Into(g any, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("cannot decode into %T, it must be a non-nil pointer", v)
+	}
+	return assign(rv.Elem(), g)
+}
+
+func assign(dst reflect.Value, g any) error {
+	if g == nil {
+		switch dst.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+			dst.SetZero()
+		}
+		return nil
+	}
+
+	if s, ok := g.(string); ok {
+		if u, ok := unmarshaler(dst, textUnmarshalerType); ok {
+			return u.(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		}
+	}
+	if u, ok := unmarshaler(dst, jsonUnmarshalerType); ok {
+		b, err := json.Marshal(g)
+		if err != nil {
+			return err
+		}
+		return u.(json.Unmarshaler).UnmarshalJSON(b)
+	}
+
+	switch dst.Kind() {
+	case reflect.Pointer:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return assign(dst.Elem(), g)
+	case reflect.Interface:
+		if dst.NumMethod() != 0 {
+			break
+		}
+		dst.Set(reflect.ValueOf(g))
+		return nil
+	case reflect.Bool:
+		if b, ok := g.(bool); ok {
+			dst.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, ok := toInt(g); ok && !dst.OverflowInt(n) {
+			dst.SetInt(n)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n, ok := toUint(g); ok && !dst.OverflowUint(n) {
+			dst.SetUint(n)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, ok := toFloat(g); ok {
+			dst.SetFloat(f)
+			return nil
+		}
+	case reflect.String:
+		switch g := g.(type) {
+		case string:
+			dst.SetString(g)
+			return nil
+		case []byte:
+			dst.SetString(string(g))
+			return nil
+		}
+	case reflect.Slice:
+		return assignSlice(dst, g)
+	case reflect.Array:
+		return assignArray(dst, g)
+	case reflect.Map:
+		return assignMap(dst, g)
+	case reflect.Struct:
+		return assignStruct(dst, g)
+	}
+
+	return mismatch(g, dst.Type())
+}
+
+func unmarshaler(rv reflect.Value, iface reflect.Type) (any, bool) {
+	if rv.Kind() != reflect.Pointer && rv.CanAddr() && reflect.PointerTo(rv.Type()).Implements(iface) {
+		return rv.Addr().Interface(), true
+	}
+	if rv.Kind() == reflect.Pointer && rv.Type().Implements(iface) {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return rv.Interface(), true
+	}
+	return nil, false
+}
+
+func mismatch(g any, t reflect.Type) error {
+	kind := fmt.Sprintf("%T", g)
+	switch g.(type) {
+	case int64, uint64, float64:
+		kind = "number"
+	case []any:
+		kind = "array"
+	case map[string]any:
+		kind = "map"
+	case []byte:
+		kind = "bytes"
+	}
+	return fmt.Errorf("cannot decode %s into Go value of type %s", kind, t)
+}
+
+func toInt(g any) (int64, bool) {
+	switch n := g.(type) {
+	case int64:
+		return n, true
+	case uint64:
+		return int64(n), n <= math.MaxInt64
+	case float64:
+		return int64(n), n == math.Trunc(n) && n >= math.MinInt64 && n <= math.MaxInt64
+	}
+	return 0, false
+}
+
+func toUint(g any) (uint64, bool) {
+	switch n := g.(type) {
+	case int64:
+		return uint64(n), n >= 0
+	case uint64:
+		return n, true
+	case float64:
+		return uint64(n), n == math.Trunc(n) && n >= 0 && n <= math.MaxUint64
+	}
+	return 0, false
+}
+
+func toFloat(g any) (float64, bool) {
+	switch n := g.(type) {
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func assignSlice(dst reflect.Value, g any) error {
+	if dst.Type().Elem().Kind() == reflect.Uint8 {
+		switch g := g.(type) {
+		case []byte:
+			dst.SetBytes(slices.Clone(g))
+			return nil
+		case string:
+			// INFO: like encoding/json, so that peers sending bytes as base64 text still work
+			b, err := base64.StdEncoding.DecodeString(g)
+			if err != nil {
+				return fmt.Errorf("cannot decode string into Go value of type %s: %w", dst.Type(), err)
+			}
+			dst.SetBytes(b)
+			return nil
+		}
+	}
+
+	arr, ok := g.([]any)
+	if !ok {
+		return mismatch(g, dst.Type())
+	}
+
+	s := reflect.MakeSlice(dst.Type(), len(arr), len(arr))
+	for i := range arr {
+		if err := assign(s.Index(i), arr[i]); err != nil {
+			return err
+		}
+	}
+	dst.Set(s)
+	return nil
+}
+
+func assignArray(dst reflect.Value, g any) error {
+	if b, ok := g.([]byte); ok && dst.Type().Elem().Kind() == reflect.Uint8 {
+		dst.SetZero()
+		reflect.Copy(dst, reflect.ValueOf(b))
+		return nil
+	}
+
+	arr, ok := g.([]any)
+	if !ok {
+		return mismatch(g, dst.Type())
+	}
+
+	dst.SetZero()
+	for i := range min(len(arr), dst.Len()) {
+		if err := assign(dst.Index(i), arr[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func assignMap(dst reflect.Value, g any) error {
+	m, ok := g.(map[string]any)
+	if !ok {
+		return mismatch(g, dst.Type())
+	}
+
+	t := dst.Type()
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMapWithSize(t, len(m)))
+	}
+
+	for k, v := range m {
+		key := reflect.New(t.Key()).Elem()
+		if err := assignMapKey(key, k); err != nil {
+			return err
+		}
+
+		value := reflect.New(t.Elem()).Elem()
+		if err := assign(value, v); err != nil {
+			return err
+		}
+		dst.SetMapIndex(key, value)
+	}
+	return nil
+}
+
+func assignMapKey(key reflect.Value, k string) error {
+	if key.Kind() == reflect.String {
+		key.SetString(k)
+		return nil
+	}
+	if u, ok := unmarshaler(key, textUnmarshalerType); ok {
+		return u.(encoding.TextUnmarshaler).UnmarshalText([]byte(k))
+	}
+
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(k, 10, 64)
+		if err != nil || key.OverflowInt(n) {
+			return fmt.Errorf("cannot decode map key %q into Go value of type %s", k, key.Type())
+		}
+		key.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(k, 10, 64)
+		if err != nil || key.OverflowUint(n) {
+			return fmt.Errorf("cannot decode map key %q into Go value of type %s", k, key.Type())
+		}
+		key.SetUint(n)
+		return nil
+	}
+	return fmt.Errorf("unsupported map key type %s", key.Type())
+}
+
+func assignStruct(dst reflect.Value, g any) error {
+	m, ok := g.(map[string]any)
+	if !ok {
+		return mismatch(g, dst.Type())
+	}
+
+	fields := cachedFields(dst.Type())
+	for k, v := range m {
+		f, ok := lookupField(fields, k)
+		if !ok {
+			continue
+		}
+
+		fv, ok := fieldByIndex(dst, f.index, true)
+		if !ok {
+			continue
+		}
+		if err := assign(fv, v); err != nil {
+			return fmt.Errorf("field %q: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// lookupField finds field by its exact name, or else case-insensitively, like encoding/json does
+func lookupField(fields []field, name string) (field, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
@@ -0,0 +1,340 @@
+// Package msgpack provides a dependency free [MessagePack](https://msgpack.org) codec for ivy.
+//
+// Values are encoded like encoding/json would (honouring `json` struct tags, omitempty, json.Marshaler and encoding.TextMarshaler),
+// except []byte, which is carried as MessagePack bin. Decoding into `any` gives int64 (or uint64) for integers, and []byte for bin.
+//
+// Example:
+//
+//	r.Codecs = ivy.NewCodecs()
+//	r.Codecs.Register(msgpack.MediaType, msgpack.Codec{})
+package msgpack
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/nxtcoder17/ivy"
+	"github.com/nxtcoder17/ivy/codec/internal/generic"
+)
+
+// MediaType of MessagePack bodies
+const MediaType = "application/msgpack"
+
+// Codec implements [ivy.Codec] for MessagePack
+type Codec struct{}
+
+var _ ivy.Codec = Codec{}
+
+// Encode implements ivy.Codec.
+func (Codec) Encode(w io.Writer, v any) error {
+	bw := bufio.NewWriter(w)
+	if err := generic.Encode(writer{w: bw}, v); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Decode implements ivy.Codec.
+func (Codec) Decode(r io.Reader, v any) error {
+	g, err := decode(bufio.NewReader(r), 0)
+	if err != nil {
+		return err
+	}
+	return generic.Into(g, v)
+}
+
+// Marshal encodes v as MessagePack
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := (Codec{}).Encode(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes MessagePack data into v
+func Unmarshal(data []byte, v any) error {
+	return Codec{}.Decode(bytes.NewReader(data), v)
+}
+
+// writer writes MessagePack, it implements generic.Writer
+type writer struct {
+	w *bufio.Writer
+}
+
+var _ generic.Writer = writer{}
+
+func (w writer) Nil() error {
+	return w.w.WriteByte(0xc0)
+}
+
+func (w writer) Bool(b bool) error {
+	if b {
+		return w.w.WriteByte(0xc3)
+	}
+	return w.w.WriteByte(0xc2)
+}
+
+func (w writer) Int(n int64) error {
+	return encodeInt(w.w, n)
+}
+
+func (w writer) Uint(n uint64) error {
+	if n <= math.MaxInt64 {
+		return encodeInt(w.w, int64(n))
+	}
+	return writeWithUint(w.w, 0xcf, n, 8)
+}
+
+func (w writer) Float(f float64, bits int) error {
+	if bits == 32 {
+		return writeWithUint(w.w, 0xca, uint64(math.Float32bits(float32(f))), 4)
+	}
+	return writeWithUint(w.w, 0xcb, math.Float64bits(f), 8)
+}
+
+func (w writer) String(s string) error {
+	n := len(s)
+	switch {
+	case n < 32:
+		w.w.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		writeWithUint(w.w, 0xd9, uint64(n), 1)
+	case n <= math.MaxUint16:
+		writeWithUint(w.w, 0xda, uint64(n), 2)
+	default:
+		writeWithUint(w.w, 0xdb, uint64(n), 4)
+	}
+	_, err := w.w.WriteString(s)
+	return err
+}
+
+func (w writer) Bytes(b []byte) error {
+	n := len(b)
+	switch {
+	case n <= math.MaxUint8:
+		writeWithUint(w.w, 0xc4, uint64(n), 1)
+	case n <= math.MaxUint16:
+		writeWithUint(w.w, 0xc5, uint64(n), 2)
+	default:
+		writeWithUint(w.w, 0xc6, uint64(n), 4)
+	}
+	_, err := w.w.Write(b)
+	return err
+}
+
+func (w writer) Array(n int) error {
+	switch {
+	case n < 16:
+		return w.w.WriteByte(0x90 | byte(n))
+	case n <= math.MaxUint16:
+		return writeWithUint(w.w, 0xdc, uint64(n), 2)
+	default:
+		return writeWithUint(w.w, 0xdd, uint64(n), 4)
+	}
+}
+
+func (w writer) Map(n int) error {
+	switch {
+	case n < 16:
+		return w.w.WriteByte(0x80 | byte(n))
+	case n <= math.MaxUint16:
+		return writeWithUint(w.w, 0xde, uint64(n), 2)
+	default:
+		return writeWithUint(w.w, 0xdf, uint64(n), 4)
+	}
+}
+
+func encodeInt(w *bufio.Writer, n int64) error {
+	switch {
+	case n >= 0 && n <= 127:
+		return w.WriteByte(byte(n))
+	case n < 0 && n >= -32:
+		return w.WriteByte(byte(n))
+	case n >= math.MinInt8 && n <= math.MaxInt8:
+		return writeWithUint(w, 0xd0, uint64(n), 1)
+	case n >= math.MinInt16 && n <= math.MaxInt16:
+		return writeWithUint(w, 0xd1, uint64(n), 2)
+	case n >= math.MinInt32 && n <= math.MaxInt32:
+		return writeWithUint(w, 0xd2, uint64(n), 4)
+	default:
+		return writeWithUint(w, 0xd3, uint64(n), 8)
+	}
+}
+
+// writeWithUint writes prefix byte, followed by n in big endian, with `size` bytes
+func writeWithUint(w *bufio.Writer, prefix byte, n uint64, size int) error {
+	var buf [9]byte
+	buf[0] = prefix
+	binary.BigEndian.PutUint64(buf[1:], n)
+	_, err := w.Write(append(buf[:1], buf[9-size:]...))
+	return err
+}
+
+var errUnexpectedEOF = errors.New("msgpack: unexpected end of data")
+
+// maxDepth is how deep arrays and maps can be nested, like in encoding/json, so that hostile input can not overflow the stack
+const maxDepth = 10000
+
+var errTooDeep = fmt.Errorf("msgpack: exceeded max depth of %d", maxDepth)
+
+// decode decodes a value, that is nested in depth arrays and maps
+func decode(r *bufio.Reader, depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errTooDeep
+	}
+
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, errUnexpectedEOF
+	}
+
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xe0 == 0xa0:
+		return readString(r, uint64(b&0x1f))
+	case b&0xf0 == 0x90:
+		return readArray(r, uint64(b&0x0f), depth)
+	case b&0xf0 == 0x80:
+		return readMap(r, uint64(b&0x0f), depth)
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := readUint(r, 1<<(b-0xc4))
+		if err != nil {
+			return nil, err
+		}
+		return readBytes(r, n)
+	case 0xca:
+		n, err := readUint(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(uint32(n))), nil
+	case 0xcb:
+		n, err := readUint(r, 8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(n), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := readUint(r, 1<<(b-0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if n <= math.MaxInt64 {
+			return int64(n), nil
+		}
+		return n, nil
+	case 0xd0:
+		n, err := readUint(r, 1)
+		return int64(int8(n)), err
+	case 0xd1:
+		n, err := readUint(r, 2)
+		return int64(int16(n)), err
+	case 0xd2:
+		n, err := readUint(r, 4)
+		return int64(int32(n)), err
+	case 0xd3:
+		n, err := readUint(r, 8)
+		return int64(n), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := readUint(r, 1<<(b-0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return readString(r, n)
+	case 0xdc, 0xdd:
+		n, err := readUint(r, 2<<(b-0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return readArray(r, n, depth)
+	case 0xde, 0xdf:
+		n, err := readUint(r, 2<<(b-0xde))
+		if err != nil {
+			return nil, err
+		}
+		return readMap(r, n, depth)
+	}
+
+	return nil, fmt.Errorf("msgpack: unsupported format 0x%02x", b)
+}
+
+func readUint(r *bufio.Reader, size int) (uint64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[8-size:]); err != nil {
+		return 0, errUnexpectedEOF
+	}
+	return binary.BigEndian.Uint64(buf[:]), nil
+}
+
+func readBytes(r *bufio.Reader, n uint64) ([]byte, error) {
+	if n > math.MaxInt32 {
+		return nil, fmt.Errorf("msgpack: length %d is too large", n)
+	}
+	// INFO: reading in chunks, so that a bogus length does not allocate a huge buffer upfront
+	buf := make([]byte, 0, min(n, 4096))
+	for uint64(len(buf)) < n {
+		chunk := min(n-uint64(len(buf)), 4096)
+		start := len(buf)
+		buf = append(buf, make([]byte, chunk)...)
+		if _, err := io.ReadFull(r, buf[start:]); err != nil {
+			return nil, errUnexpectedEOF
+		}
+	}
+	return buf, nil
+}
+
+func readString(r *bufio.Reader, n uint64) (string, error) {
+	b, err := readBytes(r, n)
+	return string(b), err
+}
+
+func readArray(r *bufio.Reader, n uint64, depth int) ([]any, error) {
+	arr := make([]any, 0, min(n, 1024))
+	for i := uint64(0); i < n; i++ {
+		v, err := decode(r, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+	}
+	return arr, nil
+}
+
+func readMap(r *bufio.Reader, n uint64, depth int) (map[string]any, error) {
+	m := make(map[string]any, min(n, 1024))
+	for i := uint64(0); i < n; i++ {
+		k, err := decode(r, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		v, err := decode(r, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		key, ok := k.(string)
+		if !ok {
+			key = fmt.Sprint(k)
+		}
+		m[key] = v
+	}
+	return m, nil
+}
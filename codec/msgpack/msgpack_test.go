@@ -0,0 +1,99 @@
+package msgpack
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type sample struct {
+	Data   []byte            `json:"data"`
+	Name   string            `json:"name"`
+	Count  int64             `json:"count"`
+	Neg    int               `json:"neg"`
+	Ratio  float64           `json:"ratio"`
+	OK     bool              `json:"ok"`
+	Tags   []string          `json:"tags"`
+	Labels map[string]string `json:"labels"`
+	Empty  *string           `json:"empty"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := sample{
+		Data:   []byte{0, 1, 0xff},
+		Name:   "ivy",
+		Count:  1 << 40,
+		Neg:    -200,
+		Ratio:  0.25,
+		OK:     true,
+		Tags:   []string{"a", "b"},
+		Labels: map[string]string{"env": "dev"},
+	}
+
+	b, err := Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out sample
+	if err := Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip:\n\t got: %+v\n\twant: %+v", out, in)
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	tests := []struct {
+		in   any
+		want []byte
+	}{
+		{in: nil, want: []byte{0xc0}},
+		{in: 1, want: []byte{0x01}},
+		{in: -1, want: []byte{0xff}},
+		{in: 300, want: []byte{0xd1, 0x01, 0x2c}},
+		{in: "hi", want: []byte{0xa2, 'h', 'i'}},
+		{in: []int{1, 2}, want: []byte{0x92, 0x01, 0x02}},
+		{in: map[string]bool{"a": true}, want: []byte{0x81, 0xa1, 'a', 0xc3}},
+		{in: []byte{1, 2}, want: []byte{0xc4, 0x02, 0x01, 0x02}},
+		{in: float32(1.5), want: []byte{0xca, 0x3f, 0xc0, 0x00, 0x00}},
+		{in: uint64(1 << 63), want: []byte{0xcf, 0x80, 0, 0, 0, 0, 0, 0, 0}},
+		{in: struct {
+			A int    `json:"a"`
+			B string `json:"b,omitempty"`
+			C bool   `json:"-"`
+		}{A: 1, C: true}, want: []byte{0x81, 0xa1, 'a', 0x01}},
+	}
+
+	for _, tt := range tests {
+		got, err := Marshal(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("Marshal(%v): got % x, want % x", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeDeeplyNested(t *testing.T) {
+	// nested arrays (and maps) must fail with an error, instead of overflowing the stack
+	for _, prefix := range []byte{0x91, 0x81} {
+		data := bytes.Repeat([]byte{prefix}, 5<<20)
+
+		var v any
+		if err := Unmarshal(data, &v); err == nil || !strings.Contains(err.Error(), "max depth") {
+			t.Errorf("nested 0x%02x: got error %v", prefix, err)
+		}
+	}
+
+	// nesting up to the limit is fine
+	data := append(bytes.Repeat([]byte{0x91}, maxDepth), 0x01)
+	var v any
+	if err := Unmarshal(data, &v); err != nil {
+		t.Errorf("nested up to max depth: %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+package ivy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// JSONCodec encodes and decodes with package level [JSONEncoder] and [JSONDecoder]
+type JSONCodec struct{}
+
+// Encode implements Codec.
+func (JSONCodec) Encode(w io.Writer, v any) error {
+	b, err := JSONEncoder(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Decode implements Codec.
+func (JSONCodec) Decode(r io.Reader, v any) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return JSONDecoder(b, v)
+}
+
+// XMLCodec encodes and decodes with [encoding/xml]
+type XMLCodec struct{}
+
+// Encode implements Codec.
+func (XMLCodec) Encode(w io.Writer, v any) error {
+	return xml.NewEncoder(w).Encode(v)
+}
+
+// Decode implements Codec.
+func (XMLCodec) Decode(r io.Reader, v any) error {
+	return xml.NewDecoder(r).Decode(v)
+}
+
+// NDJSONCodec encodes slices as newline delimited JSON, one element per line.
+// It decodes every line into a new element of the slice pointed to by v.
+type NDJSONCodec struct{}
+
+// Encode implements Codec.
+func (NDJSONCodec) Encode(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return enc.Encode(v)
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		if err := enc.Encode(rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode implements Codec.
+func (NDJSONCodec) Decode(r io.Reader, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Slice {
+		return json.NewDecoder(r).Decode(v)
+	}
+
+	slice := rv.Elem()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		item := reflect.New(slice.Type().Elem())
+		if err := json.Unmarshal(line, item.Interface()); err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, item.Elem()))
+	}
+
+	return scanner.Err()
+}
+
+// FormCodec encodes and decodes `application/x-www-form-urlencoded` bodies.
+// It works with [url.Values], map[string]string, map[string][]string and structs with `form` tags
+type FormCodec struct{}
+
+// Encode implements Codec.
+func (FormCodec) Encode(w io.Writer, v any) error {
+	values := url.Values{}
+
+	switch v := v.(type) {
+	case url.Values:
+		values = v
+	case map[string][]string:
+		values = v
+	case map[string]string:
+		for k := range v {
+			values.Set(k, v[k])
+		}
+	default:
+		rv := reflect.Indirect(reflect.ValueOf(v))
+		if rv.Kind() != reflect.Struct {
+			return fmt.Errorf("ivy: can not form encode %T", v)
+		}
+		if err := formValues(rv, values); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, values.Encode())
+	return err
+}
+
+func formValues(v reflect.Value, values url.Values) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fv := v.Field(i)
+
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct && sf.Tag.Get("form") == "" {
+			if err := formValues(fv, values); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, ok := formFieldName(sf)
+		if !ok {
+			continue
+		}
+
+		for fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				break
+			}
+			fv = fv.Elem()
+		}
+
+		if fv.Kind() == reflect.Pointer {
+			continue
+		}
+
+		if fv.Kind() == reflect.Slice && !fv.Type().Implements(textMarshalerType) {
+			for j := 0; j < fv.Len(); j++ {
+				s, err := formatValue(fv.Index(j))
+				if err != nil {
+					return err
+				}
+				values.Add(name, s)
+			}
+			continue
+		}
+
+		s, err := formatValue(fv)
+		if err != nil {
+			return err
+		}
+		values.Set(name, s)
+	}
+
+	return nil
+}
+
+func formFieldName(sf reflect.StructField) (string, bool) {
+	if !sf.IsExported() {
+		return "", false
+	}
+
+	tag, ok := sf.Tag.Lookup("form")
+	if !ok {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = sf.Name
+	}
+	return name, true
+}
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+func formatValue(v reflect.Value) (string, error) {
+	if v.Type().Implements(textMarshalerType) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	return fmt.Sprint(v.Interface()), nil
+}
+
+// Decode implements Codec.
+func (FormCodec) Decode(r io.Reader, v any) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	values, err := url.ParseQuery(string(b))
+	if err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case *url.Values:
+		*v = values
+		return nil
+	case *map[string][]string:
+		*v = values
+		return nil
+	case *map[string]string:
+		if *v == nil {
+			*v = make(map[string]string, len(values))
+		}
+		for k := range values {
+			(*v)[k] = values.Get(k)
+		}
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("ivy: can not form decode into %T", v)
+	}
+
+	fb := &binder{form: values}
+
+	var errs []error
+	fb.bindStruct(rv.Elem(), &errs)
+	if len(errs) > 0 {
+		return NewHTTPErrors(http.StatusBadRequest, "invalid request", errs...)
+	}
+	return nil
+}
+
+var (
+	_ Codec = JSONCodec{}
+	_ Codec = XMLCodec{}
+	_ Codec = NDJSONCodec{}
+	_ Codec = FormCodec{}
+)
@@ -0,0 +1,95 @@
+package ivy
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// PathConstraints are named constraints, that can be used in route patterns like `{id:int}`
+// Any constraint, that is not found here is compiled as a regular expression, like `{slug:[a-z-]+}`
+var PathConstraints = map[string]string{
+	"int":   `-?[0-9]+`,
+	"uint":  `[0-9]+`,
+	"alpha": `[a-zA-Z]+`,
+	"alnum": `[a-zA-Z0-9]+`,
+	"uuid":  `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+}
+
+// parseConstraints strips constraints from wildcards in pattern, so that it can be registered on http.ServeMux
+// e.g. `/users/{id:int}` becomes `/users/{id}`, with constraint `int` for wildcard `id`
+func parseConstraints(pattern string) (string, map[string]*regexp.Regexp, error) {
+	if !strings.Contains(pattern, ":") {
+		return pattern, nil, nil
+	}
+
+	var sb strings.Builder
+	var constraints map[string]*regexp.Regexp
+
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start == -1 {
+			sb.WriteString(pattern)
+			return sb.String(), constraints, nil
+		}
+
+		// constraints can have braces of their own, like `{code:[0-9]{3}}`
+		end, depth := -1, 0
+		for i := start; i < len(pattern); i++ {
+			if pattern[i] == '{' {
+				depth++
+			}
+			if pattern[i] == '}' {
+				depth--
+				if depth == 0 {
+					end = i
+					break
+				}
+			}
+		}
+		if end == -1 {
+			return "", nil, fmt.Errorf("unclosed wildcard in pattern %q", pattern)
+		}
+
+		sb.WriteString(pattern[:start])
+
+		wildcard := pattern[start+1 : end]
+		pattern = pattern[end+1:]
+
+		name, constraint, ok := strings.Cut(wildcard, ":")
+		sb.WriteString("{" + name + "}")
+		if !ok {
+			continue
+		}
+
+		expr, ok := PathConstraints[constraint]
+		if !ok {
+			expr = constraint
+		}
+
+		re, err := regexp.Compile(`^(?:` + expr + `)$`)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid constraint for wildcard %q: %w", name, err)
+		}
+
+		if constraints == nil {
+			constraints = make(map[string]*regexp.Regexp, 1)
+		}
+		constraints[strings.TrimSuffix(name, "...")] = re
+	}
+}
+
+// withConstraints responds with router's NotFound handler, when any of the path params does not satisfy its constraint
+func (r *Router) withConstraints(constraints map[string]*regexp.Regexp, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		for name, re := range constraints {
+			if !re.MatchString(req.PathValue(name)) {
+				r.base().chainHandlers(r.notFoundHandler())(w, req)
+				return
+			}
+		}
+
+		next(w, req)
+	}
+}
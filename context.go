@@ -2,6 +2,8 @@ package ivy
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"log/slog"
@@ -14,6 +16,9 @@ type Context struct {
 	request  *http.Request
 	response http.ResponseWriter
 
+	// writer tracks state of the response, response is the same writer, unless replaced with SetResponseWriter
+	writer *ResponseWriter
+
 	// Alias to request.Context()
 	// It allows user to pass ivy Context in place of context.Context
 	context.Context
@@ -22,6 +27,18 @@ type Context struct {
 	handlerIdx int
 	next       func(c *Context) error
 
+	// router, that is serving this request
+	router *Router
+
+	// cleanups run once the handler chain returns, see onDone
+	cleanups []func()
+
+	// afterResponse run once the response is written, see AfterResponse
+	afterResponse []func()
+
+	// pattern is shared by contexts of mounted routers serving the same request, see RoutePattern
+	pattern *routePattern
+
 	// Logger is in context to allow middlewares to add extra key value pairs to the logging context
 	Logger *slog.Logger
 
@@ -32,19 +49,30 @@ type Context struct {
 
 type ivyContextKey string
 
+// keys of request context values, that let mounted routers (and http.Handlers in between) share KV store and route pattern of the request
+const (
+	kvCtxKey      ivyContextKey = "ivy.ctx.kv"
+	patternCtxKey ivyContextKey = "ivy.ctx.pattern"
+)
+
 func newContext(r *http.Request, w http.ResponseWriter) *Context {
+	// INFO: mounted ivy routers get the writer of their parent's context, tracking it once is enough
+	rw, ok := w.(*ResponseWriter)
+	if !ok {
+		rw = NewResponseWriter(w)
+	}
+
 	ctx := &Context{
 		Context:    r.Context(),
 		request:    r,
-		response:   w,
+		response:   rw,
+		writer:     rw,
 		handlerIdx: 0,
 		next:       nil,
 		KV:         &KV{},
 		Logger:     Logger,
 	}
 
-	kvCtxKey := ivyContextKey("ivy.ctx.kv")
-
 	// INFO: this is needed to ensure that when we are converting from ivy.Handler to http.HandlerFunc, or vice-versa, we can have KV store set by previous middlewares
 	kv := r.Context().Value(kvCtxKey)
 	if kv != nil {
@@ -58,12 +86,63 @@ func newContext(r *http.Request, w http.ResponseWriter) *Context {
 
 	vctx := context.WithValue(ctx.Context, kvCtxKey, ctx.KV)
 
+	if rp, ok := r.Context().Value(patternCtxKey).(*routePattern); ok {
+		ctx.pattern = rp
+	} else {
+		ctx.pattern = &routePattern{}
+		vctx = context.WithValue(vctx, patternCtxKey, ctx.pattern)
+	}
+
 	ctx.request = r.WithContext(vctx)
 	ctx.Context = vctx
 
 	return ctx
 }
 
+type routePattern struct {
+	value string
+}
+
+// RoutePattern returns the pattern of the route, that matched the request, like `GET /v2/users/{id}`.
+// Unlike http.Request.Pattern, it includes paths that routers are mounted at.
+//
+// Middlewares of a router see the pattern of a mounted router's route, once c.Next() returns.
+// It is empty, when no route matched the request.
+func (c *Context) RoutePattern() string {
+	return c.pattern.value
+}
+
+// onDone registers fn to run once the handler chain returns, i.e. before the response is finished
+func (c *Context) onDone(fn func()) {
+	c.cleanups = append(c.cleanups, fn)
+}
+
+// AfterResponse registers fn to run once the response is written, i.e. after router's ErrorHandler has responded
+// to the error returned by the handler chain (if any). Functions run in reverse order of registration, like deferred calls.
+//
+// Example:
+//
+//	c.AfterResponse(func() {
+//	    slog.Info("responded", "status", c.StatusCode(), "bytes", c.BytesWritten())
+//	})
+func (c *Context) AfterResponse(fn func()) {
+	c.afterResponse = append(c.afterResponse, fn)
+}
+
+func (c *Context) runAfterResponse() {
+	for i := len(c.afterResponse) - 1; i >= 0; i-- {
+		c.afterResponse[i]()
+	}
+	c.afterResponse = nil
+}
+
+func (c *Context) runCleanups() {
+	for i := len(c.cleanups) - 1; i >= 0; i-- {
+		c.cleanups[i]()
+	}
+	c.cleanups = nil
+}
+
 // Calling Next() calls the next middleware in request handler chain
 func (c *Context) Next() error {
 	if c.next != nil {
@@ -78,6 +157,15 @@ func (c *Context) PathParam(key string) string {
 	return c.request.PathValue(key)
 }
 
+// URLFor builds URL path for a named route, see [Router.URL]
+// lookup starts from the top most router, so mount prefixes are always included
+func (c *Context) URLFor(name string, params ...any) (string, error) {
+	if c.router == nil {
+		return "", fmt.Errorf("no router attached to the context")
+	}
+	return c.router.root().URL(name, params...)
+}
+
 // QueryParam is like this `id` in this route path `/resource?id=hello-world`
 func (c *Context) QueryParam(key string) string {
 	return c.request.URL.Query().Get(key)
@@ -89,14 +177,28 @@ func (c *Context) Request() *http.Request {
 	return c.request
 }
 
-// ResponseWriter() returns original http response writer
+// SetContext replaces request context with ctx, for the next handlers in chain, and http.Handlers they call.
+// ctx must be derived from c.Request().Context(), otherwise request level values (like KV) are lost,
+// and not from c itself, as that makes c its own parent, and value lookups would never end.
+//
+// Example:
+//
+//	c.SetContext(context.WithValue(c.Request().Context(), userKey, user))
+func (c *Context) SetContext(ctx context.Context) {
+	c.Context = ctx
+	c.request = c.request.WithContext(ctx)
+}
+
+// ResponseWriter() returns http response writer, which tracks the response state (see [Context.Committed])
 // for feature parity, until ivy gets a rigid API design
 func (c *Context) ResponseWriter() http.ResponseWriter {
 	return c.response
 }
 
 // SetResponseWriter must only be used when you want a custom response writer instead of normal http.ResponseWriter
-// e.g use cases such as request logger
+// e.g use cases such as response buffering
+//
+// Response state (see [Context.Committed]) is only tracked, for what reaches the writer returned by [Context.ResponseWriter]
 func (c *Context) SetResponseWriter(rw http.ResponseWriter) {
 	c.response = rw
 }
@@ -144,13 +246,39 @@ func (c *Context) Body() io.ReadCloser {
 	return c.request.Body
 }
 
+// ParseBodyInto decodes request body into v, with the codec picked as per request's `Content-Type` header (defaults to JSON),
+// and then validates it with [Validate]
+//
+// When no codec is registered for the content type, it returns an [HTTPError] with status code 415,
+// and when body is larger than MaxBodySize of the router (or route), it returns one with status code 413
 func (c *Context) ParseBodyInto(v any) error {
-	b, err := io.ReadAll(c.request.Body)
-	if err != nil {
+	if err := c.decodeBody(v); err != nil {
 		return err
 	}
 
-	return JSONDecoder(b, v)
+	return Validate(v)
+}
+
+// decodeBody is ParseBodyInto without validation
+func (c *Context) decodeBody(v any) error {
+	contentType := c.request.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = MediaTypeJSON
+	}
+
+	codec, ok := c.codecs().Lookup(contentType)
+	if !ok {
+		return NewHTTPError(http.StatusUnsupportedMediaType, fmt.Sprintf("unsupported content type %q", contentType))
+	}
+
+	if err := codec.Decode(c.request.Body, v); err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			return NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("request body is larger than %d bytes", maxBytesError.Limit))
+		}
+		return err
+	}
+	return nil
 }
 
 // BodyParser is alias for ParseBodyInto
@@ -166,9 +294,8 @@ func (c *Context) Write(p []byte) (n int, err error) {
 var _ io.Writer = (*Context)(nil)
 
 func (c *Context) Flush() {
-	if v, ok := c.response.(http.Flusher); ok {
-		v.Flush()
-	}
+	// INFO: response controller also finds Flusher of writers, that are wrapped by a custom response writer
+	http.NewResponseController(c.response).Flush()
 }
 
 var _ http.Flusher = (*Context)(nil)
@@ -1,5 +1,10 @@
 package ivy
 
+import (
+	"fmt"
+	"strings"
+)
+
 type joinErrors interface {
 	Unwrap() []error
 }
@@ -47,3 +52,58 @@ var _ HTTPError = (*httpError)(nil)
 func NewHTTPError(code int, msg string) HTTPError {
 	return &httpError{code, msg}
 }
+
+type httpErrors struct {
+	httpError
+	errs []error
+}
+
+// Error implements error.
+func (h *httpErrors) Error() string {
+	var sb strings.Builder
+	sb.WriteString(h.message)
+	for i := range h.errs {
+		if i == 0 {
+			sb.WriteString(": ")
+		} else {
+			sb.WriteString("; ")
+		}
+		sb.WriteString(h.errs[i].Error())
+	}
+	return sb.String()
+}
+
+// Unwrap allows [ErrorFormatJSON] to list all the errors
+func (h *httpErrors) Unwrap() []error {
+	return h.errs
+}
+
+var (
+	_ HTTPError  = (*httpErrors)(nil)
+	_ joinErrors = (*httpErrors)(nil)
+)
+
+// NewHTTPErrors creates an HTTPError, that wraps multiple errors like a per field error list
+func NewHTTPErrors(code int, msg string, errs ...error) HTTPError {
+	return &httpErrors{httpError: httpError{code, msg}, errs: errs}
+}
+
+// FieldError is the error for a single struct field, while binding or validating a request
+type FieldError struct {
+	// Field is the name of field, as clients see it, e.g. name of the query param
+	Field string
+
+	// Source is where the field is read from, like "query", "path", "header", "cookie", "form" or "body"
+	Source string
+
+	Err error
+}
+
+// Error implements error.
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s %q: %v", e.Source, e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
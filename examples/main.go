@@ -1,10 +1,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math/rand/v2"
-	"net/http"
 	"path/filepath"
 	"time"
 
@@ -41,7 +41,10 @@ func main() {
 		return c.SendString(fmt.Sprintf("dir: %s, message: %s", c.KV.Get("dir"), c.QueryParam("message")))
 	})
 
-	if err := http.ListenAndServe(":8089", r); err != nil {
+	srv := ivy.NewServer(r, ivy.ServerOptions{Addr: ":8089"})
+	r.Get("/_ready", srv.ReadinessHandler())
+
+	if err := srv.ListenAndServe(context.Background()); err != nil {
 		log.Fatal(err)
 	}
 }
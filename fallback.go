@@ -0,0 +1,230 @@
+package ivy
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+var standardMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodTrace,
+}
+
+// DefaultNotFoundHandler is used when no route matches request path, and router does not have a NotFound handler
+var DefaultNotFoundHandler Handler = func(c *Context) error {
+	return NewHTTPError(http.StatusNotFound, "404 page not found")
+}
+
+// DefaultMethodNotAllowedHandler is used when request path matches a route, but not with the request method,
+// and router does not have a MethodNotAllowed handler
+var DefaultMethodNotAllowedHandler Handler = func(c *Context) error {
+	return NewHTTPError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+}
+
+// NotFound sets the handler, that runs when no route matches the request path
+// It runs after router's middlewares, and errors returned from it go to router's ErrorHandler
+func (r *Router) NotFound(h Handler) {
+	r.base().notFound = h
+}
+
+// MethodNotAllowed sets the handler, that runs when a route matches the request path, but not the request method
+// `Allow` header is already set on the response, when it runs
+//
+// It runs after middlewares of the router (or group), that the most specific of the matched routes is registered on,
+// and errors returned from it go to their ErrorHandler
+func (r *Router) MethodNotAllowed(h Handler) {
+	r.base().methodNotAllowed = h
+}
+
+func (r *Router) notFoundHandler() Handler {
+	for router := r.base(); router != nil; {
+		if router.notFound != nil {
+			return router.notFound
+		}
+		if router.mountedAt == nil {
+			break
+		}
+		router = router.mountedAt.parent.base()
+	}
+	return DefaultNotFoundHandler
+}
+
+func (r *Router) methodNotAllowedHandler() Handler {
+	for router := r.base(); router != nil; {
+		if router.methodNotAllowed != nil {
+			return router.methodNotAllowed
+		}
+		if router.mountedAt == nil {
+			break
+		}
+		router = router.mountedAt.parent.base()
+	}
+	return DefaultMethodNotAllowedHandler
+}
+
+// INFO: http.ServeMux answers unmatched requests itself, with plain text 404/405 responses.
+// To keep them consistent with other routes, a catch-all "/" pattern is registered on the mux, that runs our own fallback handlers
+// through the router's middleware chain. Being the least specific pattern, it only matches requests, that no route matches,
+// and the mux still redirects paths like /tree to /tree/ on its own.
+//
+// It is registered as the router is created, so a catch-all route without method (like `r.Handle("/", h)`), that would conflict
+// with it on the mux, is kept on the router instead (see [Router.handle]), and the fallback route hands unmatched requests over to it.
+func (r *Router) registerFallback() {
+	r.mux.HandleFunc("/", r.serveFallback)
+}
+
+// catchAllRoute is a catch-all route without method, like `/` or `/{path...}`
+type catchAllRoute struct {
+	pattern string
+	handler http.Handler
+}
+
+// handle registers handler for pattern on the mux, or keeps it as the catch-all route of the router, when pattern is one
+func (r *Router) handle(pattern string, handler http.Handler) {
+	if !isCatchAllPattern(pattern) {
+		r.mux.Handle(pattern, handler)
+		return
+	}
+
+	catchAll := &catchAllRoute{pattern: pattern, handler: handler}
+	if !r.base().catchAll.CompareAndSwap(nil, catchAll) {
+		panic(fmt.Sprintf("ivy: pattern %q conflicts with pattern %q", pattern, r.base().catchAll.Load().pattern))
+	}
+}
+
+func isCatchAllPattern(pattern string) bool {
+	if pattern == "/" {
+		return true
+	}
+	wildcard, ok := strings.CutPrefix(pattern, "/{")
+	return ok && strings.HasSuffix(wildcard, "...}") && !strings.Contains(wildcard, "/")
+}
+
+// allowedMethods finds methods of registered routes, that match request path, along with the router (or group)
+// of the most specific of those routes, whose middlewares answer the request
+func (r *Router) allowedMethods(req *http.Request) ([]string, *Router) {
+	matched := map[string]bool{}
+	candidates := slices.Clone(standardMethods)
+
+	owner := r.base()
+	ownerPattern := ""
+
+	for _, route := range r.base().routes {
+		if route.method == "" || !matchesPattern(route.pattern, req.URL.Path) {
+			continue
+		}
+
+		if route.router != nil && len(route.pattern) > len(ownerPattern) {
+			owner, ownerPattern = route.router, route.pattern
+		}
+
+		matched[route.method] = true
+		// INFO: http.ServeMux serves HEAD requests with GET routes
+		if route.method == http.MethodGet {
+			matched[http.MethodHead] = true
+		}
+		if !slices.Contains(candidates, route.method) {
+			candidates = append(candidates, route.method)
+		}
+	}
+
+	var allowed []string
+	for _, method := range candidates {
+		if method != req.Method && matched[method] {
+			allowed = append(allowed, method)
+		}
+	}
+
+	if len(allowed) > 0 {
+		allowed = append(allowed, http.MethodOptions)
+	}
+
+	return allowed, owner
+}
+
+// matchesPattern reports whether path matches path of a http.ServeMux pattern, like `/users/{id}`, `/files/{path...}` or `/static/`
+func matchesPattern(pattern string, path string) bool {
+	// patterns with a host are not matched here
+	if !strings.HasPrefix(pattern, "/") || !strings.HasPrefix(path, "/") {
+		return false
+	}
+
+	patternSegments := strings.Split(pattern[1:], "/")
+	segments := strings.Split(path[1:], "/")
+
+	for i, ps := range patternSegments {
+		last := i == len(patternSegments)-1
+
+		switch {
+		case last && ps == "":
+			// trailing slash matches any path under it
+			return len(segments) > i
+		case ps == "{$}":
+			return len(segments) == i+1 && segments[i] == ""
+		case last && strings.HasPrefix(ps, "{") && strings.HasSuffix(ps, "...}"):
+			return len(segments) > i
+		}
+
+		if i >= len(segments) {
+			return false
+		}
+
+		if strings.HasPrefix(ps, "{") && strings.HasSuffix(ps, "}") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+
+		if ps != segments[i] {
+			return false
+		}
+	}
+
+	return len(segments) == len(patternSegments)
+}
+
+func (r *Router) serveFallback(w http.ResponseWriter, req *http.Request) {
+	// INFO: request carries the pattern of the catch-all fallback route (or of the mount point, in a mounted router), though no route has matched it
+	if req.Pattern != "" {
+		unmatched := *req
+		unmatched.Pattern = ""
+		req = &unmatched
+	}
+
+	if catchAll := r.base().catchAll.Load(); catchAll != nil {
+		matched := *req
+		matched.Pattern = catchAll.pattern
+		if wildcard, ok := strings.CutPrefix(catchAll.pattern, "/{"); ok {
+			matched.SetPathValue(strings.TrimSuffix(wildcard, "...}"), strings.TrimPrefix(req.URL.Path, "/"))
+		}
+		catchAll.handler.ServeHTTP(w, &matched)
+		return
+	}
+
+	allowed, owner := r.allowedMethods(req)
+	if len(allowed) == 0 {
+		r.base().chainHandlers(r.notFoundHandler())(w, req)
+		return
+	}
+
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+
+	// INFO: runs through middlewares of the group, that the path belongs to, so that group middlewares (like CORS) answer preflight requests
+	if req.Method == http.MethodOptions {
+		owner.chainHandlers(func(c *Context) error {
+			return c.SendStatus(http.StatusNoContent)
+		})(w, req)
+		return
+	}
+
+	owner.chainHandlers(r.methodNotAllowedHandler())(w, req)
+}
@@ -0,0 +1,283 @@
+// Package health serves liveness and readiness endpoints, that report results of registered checks as
+// [application/health+json](https://datatracker.ietf.org/doc/html/draft-inadarei-api-health-check).
+//
+// Example:
+//
+//	h := health.New(health.Options{ServiceID: "orders", Version: "1.4.2", Ready: srv.Ready})
+//	h.Register("postgres", db.PingContext)
+//	h.Register("cache", redis.Ping, health.CheckOptions{Timeout: 500 * time.Millisecond, Critical: ivy.Ptr(false)})
+//
+//	r.Get("/healthz", h.Liveness())
+//	r.Get("/readyz", h.Readiness())
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+// Status of a check, or of the whole service
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// ContentType of health responses
+const ContentType = "application/health+json"
+
+// DefaultTimeout is the timeout of a check, when it is not set in CheckOptions
+const DefaultTimeout = 2 * time.Second
+
+// Options configures a [Health]
+type Options struct {
+	// ServiceID, Version and Description are included in responses, when set
+	ServiceID   string
+	Version     string
+	Description string
+
+	// CacheTTL is how long check results are reused for, so that frequent probes do not hammer dependencies.
+	// Results are not cached, when it is zero
+	CacheTTL time.Duration
+
+	// Ready, when set, makes readiness fail without running checks, while it returns false, see [ivy.Server.Ready]
+	Ready func() bool
+}
+
+// CheckOptions configures a check
+type CheckOptions struct {
+	// Timeout of the check, defaults to [DefaultTimeout]
+	Timeout time.Duration
+
+	// Critical checks fail readiness, when they fail, while failing non-critical checks only make it warn. Defaults to true
+	Critical *bool
+}
+
+func (o *CheckOptions) withDefaultsIfMissing() {
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultTimeout
+	}
+	if o.Critical == nil {
+		o.Critical = ivy.Ptr(true)
+	}
+}
+
+type check struct {
+	name string
+	fn   func(ctx context.Context) error
+	opts CheckOptions
+}
+
+// Health holds named checks, and serves their results
+type Health struct {
+	opts Options
+
+	mu     sync.RWMutex
+	checks []check
+
+	// runMu makes concurrent requests share a single run of checks
+	runMu     sync.Mutex
+	cached    *Response
+	cachedTil time.Time
+}
+
+// New creates a Health, with no checks
+func New(opts ...Options) *Health {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return &Health{opts: opt}
+}
+
+// Register adds a check, called name. Check passes when fn returns nil, within its timeout
+func (h *Health) Register(name string, fn func(ctx context.Context) error, opts ...CheckOptions) {
+	var opt CheckOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	opt.withDefaultsIfMissing()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, check{name: name, fn: fn, opts: opt})
+}
+
+// Response is the application/health+json body
+type Response struct {
+	Status      Status                   `json:"status"`
+	Version     string                   `json:"version,omitempty"`
+	ServiceID   string                   `json:"serviceId,omitempty"`
+	Description string                   `json:"description,omitempty"`
+	Output      string                   `json:"output,omitempty"`
+	Checks      map[string][]CheckResult `json:"checks,omitempty"`
+}
+
+// CheckResult is the result of a single check
+type CheckResult struct {
+	Status        Status    `json:"status"`
+	Time          time.Time `json:"time"`
+	ObservedValue int64     `json:"observedValue"`
+	ObservedUnit  string    `json:"observedUnit"`
+	Output        string    `json:"output,omitempty"`
+}
+
+// Run runs all the checks in parallel, or returns results of the previous run, while they are within CacheTTL
+func (h *Health) Run(ctx context.Context) *Response {
+	h.runMu.Lock()
+	defer h.runMu.Unlock()
+
+	if h.cached != nil && time.Now().Before(h.cachedTil) {
+		return h.cached
+	}
+
+	h.mu.RLock()
+	checks := append([]check(nil), h.checks...)
+	h.mu.RUnlock()
+
+	resp := h.response(StatusPass)
+	resp.Checks = make(map[string][]CheckResult, len(checks))
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runCheck(ctx, checks[i])
+		}()
+	}
+	wg.Wait()
+
+	for i, c := range checks {
+		result := results[i]
+		if result.Status == StatusFail && !*c.opts.Critical {
+			result.Status = StatusWarn
+		}
+		resp.Checks[c.name] = append(resp.Checks[c.name], result)
+		resp.Status = worse(resp.Status, result.Status)
+	}
+
+	if resp.Status == StatusFail {
+		resp.Output = "failing checks: " + failing(resp.Checks)
+	}
+
+	if h.opts.CacheTTL > 0 {
+		h.cached = resp
+		h.cachedTil = time.Now().Add(h.opts.CacheTTL)
+	}
+	return resp
+}
+
+func runCheck(ctx context.Context, c check) CheckResult {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	// INFO: result is waited for in a select, so that checks ignoring ctx still time out
+	errCh := make(chan error, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				errCh <- fmt.Errorf("panic: %v", rec)
+			}
+		}()
+		errCh <- c.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", c.opts.Timeout)
+	}
+
+	result := CheckResult{
+		Status:        StatusPass,
+		Time:          start.UTC(),
+		ObservedValue: time.Since(start).Milliseconds(),
+		ObservedUnit:  "ms",
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Output = err.Error()
+	}
+	return result
+}
+
+func worse(a, b Status) Status {
+	rank := map[Status]int{StatusPass: 0, StatusWarn: 1, StatusFail: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+func failing(checks map[string][]CheckResult) string {
+	var names []string
+	for name, results := range checks {
+		for _, r := range results {
+			if r.Status == StatusFail {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return fmt.Sprint(names)
+}
+
+func (h *Health) response(status Status) *Response {
+	return &Response{
+		Status:      status,
+		Version:     h.opts.Version,
+		ServiceID:   h.opts.ServiceID,
+		Description: h.opts.Description,
+	}
+}
+
+// Liveness reports that the process is up and serving requests, without running any checks
+func (h *Health) Liveness() ivy.Handler {
+	return func(c *ivy.Context) error {
+		return send(c, h.response(StatusPass))
+	}
+}
+
+// Readiness runs the checks, and responds with 503 Service Unavailable, when any critical one of them fails
+func (h *Health) Readiness() ivy.Handler {
+	return func(c *ivy.Context) error {
+		if h.opts.Ready != nil && !h.opts.Ready() {
+			resp := h.response(StatusFail)
+			resp.Output = "not ready"
+			return send(c, resp)
+		}
+
+		// INFO: results may be cached for other requests, so they must not be cut short by this request going away
+		return send(c, h.Run(context.WithoutCancel(c)))
+	}
+}
+
+func send(c *ivy.Context, resp *Response) error {
+	b, err := ivy.JSONEncoder(resp)
+	if err != nil {
+		return err
+	}
+
+	code := http.StatusOK
+	if resp.Status == StatusFail {
+		code = http.StatusServiceUnavailable
+	}
+
+	c.SetHeader("Content-Type", ContentType)
+	c.SetHeader("Cache-Control", "no-store")
+	return c.Status(code).SendBytes(b)
+}
@@ -0,0 +1,137 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func serve(t *testing.T, handler ivy.Handler) (int, Response) {
+	t.Helper()
+
+	r := ivy.NewRouter()
+	r.Get("/health", handler)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	if ct := w.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("content type: got %q", ct)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return w.Code, resp
+}
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name       string
+		register   func(h *Health)
+		wantCode   int
+		wantStatus Status
+		wantChecks map[string]Status
+	}{
+		{
+			name: "1. all checks pass",
+			register: func(h *Health) {
+				h.Register("db", func(ctx context.Context) error { return nil })
+				h.Register("cache", func(ctx context.Context) error { return nil })
+			},
+			wantCode:   http.StatusOK,
+			wantStatus: StatusPass,
+			wantChecks: map[string]Status{"db": StatusPass, "cache": StatusPass},
+		},
+		{
+			name: "2. failing non-critical check warns",
+			register: func(h *Health) {
+				h.Register("db", func(ctx context.Context) error { return nil })
+				h.Register("cache", func(ctx context.Context) error { return errors.New("connection refused") }, CheckOptions{Critical: ivy.Ptr(false)})
+			},
+			wantCode:   http.StatusOK,
+			wantStatus: StatusWarn,
+			wantChecks: map[string]Status{"db": StatusPass, "cache": StatusWarn},
+		},
+		{
+			name: "3. failing critical check fails",
+			register: func(h *Health) {
+				h.Register("db", func(ctx context.Context) error { return errors.New("connection refused") })
+				h.Register("cache", func(ctx context.Context) error { return errors.New("connection refused") }, CheckOptions{Critical: ivy.Ptr(false)})
+			},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: StatusFail,
+			wantChecks: map[string]Status{"db": StatusFail, "cache": StatusWarn},
+		},
+		{
+			name: "4. check ignoring its context times out",
+			register: func(h *Health) {
+				h.Register("slow", func(ctx context.Context) error {
+					time.Sleep(200 * time.Millisecond)
+					return nil
+				}, CheckOptions{Timeout: 10 * time.Millisecond})
+			},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: StatusFail,
+			wantChecks: map[string]Status{"slow": StatusFail},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(Options{ServiceID: "orders", Version: "1.0"})
+			tt.register(h)
+
+			code, resp := serve(t, h.Readiness())
+			if code != tt.wantCode || resp.Status != tt.wantStatus {
+				t.Errorf("got %d %q, want %d %q", code, resp.Status, tt.wantCode, tt.wantStatus)
+			}
+			if resp.ServiceID != "orders" || resp.Version != "1.0" {
+				t.Errorf("service info: got %q %q", resp.ServiceID, resp.Version)
+			}
+			for name, want := range tt.wantChecks {
+				if got := resp.Checks[name]; len(got) != 1 || got[0].Status != want {
+					t.Errorf("check %q: got %+v, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadiness_CacheAndReady(t *testing.T) {
+	var runs atomic.Int32
+	var ready atomic.Bool
+	ready.Store(true)
+
+	h := New(Options{CacheTTL: time.Minute, Ready: ready.Load})
+	h.Register("db", func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+
+	for range 3 {
+		if code, _ := serve(t, h.Readiness()); code != http.StatusOK {
+			t.Errorf("got %d", code)
+		}
+	}
+	if runs.Load() != 1 {
+		t.Errorf("check must run once within CacheTTL, ran %d times", runs.Load())
+	}
+
+	ready.Store(false)
+	if code, resp := serve(t, h.Readiness()); code != http.StatusServiceUnavailable || resp.Output != "not ready" {
+		t.Errorf("got %d %+v", code, resp)
+	}
+
+	if code, resp := serve(t, h.Liveness()); code != http.StatusOK || resp.Status != StatusPass {
+		t.Errorf("liveness: got %d %+v", code, resp)
+	}
+}
@@ -0,0 +1,613 @@
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ToJSON converts YAML document into JSON
+func ToJSON(b []byte) ([]byte, error) {
+	p := &parser{lines: splitLines(string(b))}
+
+	p.skipBlank()
+	if p.pos >= len(p.lines) {
+		return []byte("null"), nil
+	}
+
+	v, err := p.parseBlock(p.lines[p.pos].indent)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.skipBlank(); p.pos < len(p.lines) {
+		return nil, p.errorf("unexpected content %q", p.lines[p.pos].text)
+	}
+
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes YAML document into v, as per its `json` struct tags
+func Unmarshal(b []byte, v any) error {
+	jb, err := ToJSON(b)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jb, v)
+}
+
+type line struct {
+	num    int
+	indent int
+	// text is the line content, without indentation and comments
+	text string
+	// raw is the line without indentation, it is used by block scalars, which can contain `#`
+	raw string
+}
+
+func splitLines(s string) []*line {
+	var lines []*line
+	for i, raw := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimLeft(raw, " ")
+		if trimmed == "---" || trimmed == "..." || strings.HasPrefix(trimmed, "%") {
+			// INFO: only single document streams are supported, so document markers and directives are dropped
+			trimmed = ""
+		}
+
+		lines = append(lines, &line{
+			num:    i + 1,
+			indent: len(raw) - len(trimmed),
+			text:   strings.TrimRight(stripComment(trimmed), " \t"),
+			raw:    strings.TrimRight(trimmed, " \t"),
+		})
+	}
+	return lines
+}
+
+// stripComment removes comment starting with `#`, that is not inside quotes
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" \t[{,:-", rune(s[i-1])) {
+				quote = c
+			}
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+type parser struct {
+	lines []*line
+	pos   int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	num := 0
+	if p.pos < len(p.lines) {
+		num = p.lines[p.pos].num
+	}
+	return fmt.Errorf("yaml: line %d: %s", num, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipBlank() {
+	for p.pos < len(p.lines) && p.lines[p.pos].text == "" {
+		p.pos++
+	}
+}
+
+// next returns the next non blank line, without consuming it
+func (p *parser) next() *line {
+	p.skipBlank()
+	if p.pos >= len(p.lines) {
+		return nil
+	}
+	return p.lines[p.pos]
+}
+
+func isSeqEntry(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// parseBlock parses block node, whose first line is at indent
+func (p *parser) parseBlock(indent int) (any, error) {
+	l := p.next()
+	if l == nil {
+		return nil, nil
+	}
+
+	if isSeqEntry(l.text) {
+		return p.parseSeq(indent)
+	}
+
+	if _, _, ok, err := splitKey(l.text); err != nil {
+		return nil, p.errorf("%v", err)
+	} else if ok {
+		return p.parseMap(indent)
+	}
+
+	if err := unsupportedNode(l.text); err != nil {
+		return nil, p.errorf("%v", err)
+	}
+
+	// a scalar, possibly folded over multiple lines
+	p.pos++
+	text := l.text
+	if text[0] == '[' || text[0] == '{' || text[0] == '"' || text[0] == '\'' {
+		text = p.continueFlow(text, indent)
+		return parseFlowValue(text)
+	}
+	for n := p.next(); n != nil && n.indent >= indent; n = p.next() {
+		text += " " + n.text
+		p.pos++
+	}
+	return resolvePlain(text), nil
+}
+
+func (p *parser) parseMap(indent int) (map[string]any, error) {
+	m := make(map[string]any)
+
+	for l := p.next(); l != nil && l.indent == indent; l = p.next() {
+		key, rest, ok, err := splitKey(l.text)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		if !ok {
+			return nil, p.errorf("expected a mapping key, got %q", l.text)
+		}
+		if err := unsupportedNode(key); err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		if key == "<<" {
+			return nil, p.errorf("merge key %q is not supported, as aliases are not", key)
+		}
+		if _, dup := m[key]; dup {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		p.pos++
+
+		v, err := p.parseValue(rest, indent, true)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+
+	if l := p.next(); l != nil && l.indent > indent {
+		return nil, p.errorf("bad indentation of %q", l.text)
+	}
+
+	return m, nil
+}
+
+func (p *parser) parseSeq(indent int) ([]any, error) {
+	seq := []any{}
+
+	for l := p.next(); l != nil && l.indent == indent && isSeqEntry(l.text); l = p.next() {
+		rest := strings.TrimLeft(strings.TrimPrefix(l.text, "-"), " ")
+		if rest == "" {
+			p.pos++
+			v, err := p.parseValue("", indent, false)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+			continue
+		}
+
+		// INFO: entry content like `- key: value` or `- - x` is a block node of its own, at the column it starts from
+		offset := len(l.text) - len(rest)
+		if _, _, isKey, _ := splitKey(rest); isKey || isSeqEntry(rest) {
+			l.indent += offset
+			l.text = rest
+			l.raw = l.raw[offset:]
+			v, err := p.parseBlock(l.indent)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+			continue
+		}
+
+		p.pos++
+		v, err := p.parseValue(rest, indent, false)
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, v)
+	}
+
+	return seq, nil
+}
+
+// parseValue parses value of a mapping key or sequence entry at indent, rest is the text after `key:` or `-`
+func (p *parser) parseValue(rest string, indent int, inMap bool) (any, error) {
+	switch {
+	case rest == "":
+		n := p.next()
+		if n == nil {
+			return nil, nil
+		}
+		if n.indent > indent {
+			return p.parseBlock(n.indent)
+		}
+		// INFO: sequences are allowed at the same indentation as their key
+		if inMap && n.indent == indent && isSeqEntry(n.text) {
+			return p.parseSeq(indent)
+		}
+		return nil, nil
+	case rest[0] == '|' || rest[0] == '>':
+		return p.parseBlockScalar(rest, indent)
+	case rest[0] == '[' || rest[0] == '{' || rest[0] == '"' || rest[0] == '\'':
+		return parseFlowValue(p.continueFlow(rest, indent))
+	case rest[0] == '&' || rest[0] == '*' || rest[0] == '!':
+		// INFO: line of rest is already consumed, stepping back, so that the error points to it
+		p.pos--
+		return nil, p.errorf("%v", unsupportedNode(rest))
+	}
+
+	// plain scalars can continue on more indented lines
+	for n := p.next(); n != nil && n.indent > indent; n = p.next() {
+		rest += " " + n.text
+		p.pos++
+	}
+	return resolvePlain(rest), nil
+}
+
+// continueFlow appends following lines to a flow collection or quoted scalar, until it is complete
+func (p *parser) continueFlow(text string, indent int) string {
+	for !flowComplete(text) {
+		n := p.next()
+		if n == nil || n.indent <= indent && !strings.HasPrefix(n.text, "]") && !strings.HasPrefix(n.text, "}") {
+			return text
+		}
+		text += " " + n.text
+		p.pos++
+	}
+	return text
+}
+
+func flowComplete(s string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				if quote == '\'' && i+1 < len(s) && s[i+1] == '\'' {
+					i++
+					continue
+				}
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return depth <= 0 && quote == 0
+}
+
+func (p *parser) parseBlockScalar(header string, indent int) (string, error) {
+	folded := header[0] == '>'
+	chomp := byte(0)
+	explicitIndent := 0
+	for _, c := range header[1:] {
+		switch {
+		case c == '-' || c == '+':
+			chomp = byte(c)
+		case c >= '1' && c <= '9':
+			explicitIndent = int(c - '0')
+		case c == ' ':
+		default:
+			return "", p.errorf("invalid block scalar header %q", header)
+		}
+	}
+
+	var lines []string
+	blockIndent := -1
+	if explicitIndent > 0 {
+		blockIndent = indent + explicitIndent
+	}
+
+	for ; p.pos < len(p.lines); p.pos++ {
+		l := p.lines[p.pos]
+		if l.raw == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if blockIndent == -1 {
+			blockIndent = l.indent
+		}
+		if l.indent <= indent || l.indent < blockIndent {
+			break
+		}
+		lines = append(lines, strings.Repeat(" ", l.indent-blockIndent)+l.raw)
+	}
+
+	// trailing blank lines belong to chomping, not to content
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var sb strings.Builder
+	for i, l := range lines {
+		if i > 0 {
+			prev := lines[i-1]
+			// INFO: folding joins lines with a space, except around empty and more indented lines
+			if folded && prev != "" && l != "" && prev[0] != ' ' && l[0] != ' ' {
+				sb.WriteByte(' ')
+			} else {
+				sb.WriteByte('\n')
+			}
+		}
+		sb.WriteString(l)
+	}
+
+	s := sb.String()
+	if folded {
+		// blank lines in folded scalars stand for a single line feed
+		s = strings.ReplaceAll(s, "\n\n", "\n")
+	}
+
+	switch chomp {
+	case '-':
+	case '+':
+		if len(lines) > 0 {
+			s += "\n"
+		}
+		s += strings.Repeat("\n", trailing)
+	default:
+		if len(lines) > 0 {
+			s += "\n"
+		}
+	}
+
+	return s, nil
+}
+
+// splitKey splits `key: value` line, it reports false if text is not a mapping entry
+func splitKey(text string) (key string, rest string, ok bool, err error) {
+	if text == "" || text[0] == '[' || text[0] == '{' || isSeqEntry(text) {
+		return "", "", false, nil
+	}
+
+	if text[0] == '"' || text[0] == '\'' {
+		end := quotedEnd(text)
+		if end == -1 {
+			return "", "", false, nil
+		}
+		after := strings.TrimLeft(text[end:], " ")
+		if after != ":" && !strings.HasPrefix(after, ": ") {
+			return "", "", false, nil
+		}
+		key, err := unquote(text[:end])
+		if err != nil {
+			return "", "", false, err
+		}
+		return key, strings.TrimSpace(after[1:]), true, nil
+	}
+
+	if strings.HasPrefix(text, "? ") {
+		return "", "", false, fmt.Errorf("complex mapping keys are not supported")
+	}
+
+	if i := strings.Index(text, ": "); i != -1 {
+		return strings.TrimRight(text[:i], " "), strings.TrimSpace(text[i+2:]), true, nil
+	}
+	if strings.HasSuffix(text, ":") {
+		return strings.TrimRight(text[:len(text)-1], " "), "", true, nil
+	}
+
+	return "", "", false, nil
+}
+
+// quotedEnd returns index after closing quote of quoted scalar at start of s
+func quotedEnd(s string) int {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case s[i] == q:
+			if q == '\'' && i+1 < len(s) && s[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return -1
+}
+
+func unquote(s string) (string, error) {
+	if s[0] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+
+	// INFO: YAML double quoted escapes are mostly the same as go's, except for these
+	r := strings.NewReplacer(`\/`, `/`, `\ `, ` `, `\e`, `\x1b`, `\0`, `\x00`, `\N`, `\u0085`, `\_`, ` `, `\L`, ` `, `\P`, ` `)
+	u, err := strconv.Unquote(r.Replace(s))
+	if err != nil {
+		return "", fmt.Errorf("invalid double quoted scalar %s", s)
+	}
+	return u, nil
+}
+
+// unsupportedNode returns an error naming the anchor (`&name`), alias (`*name`) or tag (`!name`) text starts with, as none of them are supported
+func unsupportedNode(text string) error {
+	var feature string
+	switch {
+	case strings.HasPrefix(text, "&"):
+		feature = "anchor"
+	case strings.HasPrefix(text, "*"):
+		feature = "alias"
+	case strings.HasPrefix(text, "!"):
+		feature = "tag"
+	default:
+		return nil
+	}
+
+	name, _, _ := strings.Cut(text, " ")
+	name, _, _ = strings.Cut(name, ",")
+	return fmt.Errorf("%s %q is not supported, anchors, aliases and tags must be written out in place", feature, strings.TrimRight(name, "]}"))
+}
+
+// resolvePlain resolves plain scalar into null, bool, number or string, as per YAML 1.2 core schema
+func resolvePlain(s string) any {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+
+	if n, err := strconv.ParseInt(s, 0, 64); err == nil && !strings.HasPrefix(strings.TrimLeft(s, "+-"), "0b") {
+		return json.Number(strconv.FormatInt(n, 10))
+	}
+
+	if strings.ContainsAny(s, "0123456789") && !strings.ContainsAny(s, "_xXpP") {
+		if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	}
+
+	return s
+}
+
+// parseFlowValue parses flow collections like `[a, b]` and `{a: 1}`, and quoted scalars
+func parseFlowValue(s string) (any, error) {
+	fp := &flowParser{s: s}
+	v, err := fp.value()
+	if err != nil {
+		return nil, err
+	}
+	if fp.skipSpace(); fp.i < len(fp.s) {
+		return nil, fmt.Errorf("yaml: unexpected %q after flow value", fp.s[fp.i:])
+	}
+	return v, nil
+}
+
+type flowParser struct {
+	s string
+	i int
+}
+
+func (fp *flowParser) skipSpace() {
+	for fp.i < len(fp.s) && (fp.s[fp.i] == ' ' || fp.s[fp.i] == '\t') {
+		fp.i++
+	}
+}
+
+func (fp *flowParser) value() (any, error) {
+	fp.skipSpace()
+	if fp.i >= len(fp.s) {
+		return nil, nil
+	}
+
+	switch fp.s[fp.i] {
+	case '[':
+		fp.i++
+		seq := []any{}
+		for {
+			fp.skipSpace()
+			if fp.i < len(fp.s) && fp.s[fp.i] == ']' {
+				fp.i++
+				return seq, nil
+			}
+			v, err := fp.value()
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+			if err := fp.separator(']'); err != nil {
+				return nil, err
+			}
+		}
+	case '{':
+		fp.i++
+		m := make(map[string]any)
+		for {
+			fp.skipSpace()
+			if fp.i < len(fp.s) && fp.s[fp.i] == '}' {
+				fp.i++
+				return m, nil
+			}
+			k, err := fp.value()
+			if err != nil {
+				return nil, err
+			}
+			fp.skipSpace()
+			var v any
+			if fp.i < len(fp.s) && fp.s[fp.i] == ':' {
+				fp.i++
+				if v, err = fp.value(); err != nil {
+					return nil, err
+				}
+			}
+			m[fmt.Sprint(k)] = v
+			if err := fp.separator('}'); err != nil {
+				return nil, err
+			}
+		}
+	case '"', '\'':
+		end := quotedEnd(fp.s[fp.i:])
+		if end == -1 {
+			return nil, fmt.Errorf("yaml: unterminated quoted scalar")
+		}
+		s, err := unquote(fp.s[fp.i : fp.i+end])
+		fp.i += end
+		return s, err
+	}
+
+	if err := unsupportedNode(fp.s[fp.i:]); err != nil {
+		return nil, fmt.Errorf("yaml: %w", err)
+	}
+
+	start := fp.i
+	for fp.i < len(fp.s) {
+		c := fp.s[fp.i]
+		if c == ',' || c == ']' || c == '}' || (c == ':' && (fp.i+1 == len(fp.s) || strings.ContainsRune(" ,]}", rune(fp.s[fp.i+1])))) {
+			break
+		}
+		fp.i++
+	}
+	return resolvePlain(strings.TrimSpace(fp.s[start:fp.i])), nil
+}
+
+// separator consumes `,` between flow collection entries, or stops before closing bracket
+func (fp *flowParser) separator(closing byte) error {
+	fp.skipSpace()
+	if fp.i >= len(fp.s) {
+		return fmt.Errorf("yaml: unterminated flow collection")
+	}
+	switch fp.s[fp.i] {
+	case ',':
+		fp.i++
+		return nil
+	case closing:
+		return nil
+	}
+	return fmt.Errorf("yaml: unexpected %q in flow collection", fp.s[fp.i])
+}
@@ -0,0 +1,204 @@
+// Package yaml converts between JSON and a YAML subset, it is just enough for serving and reading OpenAPI documents.
+//
+// Supported YAML subset is block mappings and sequences, flow mappings and sequences, plain, single and double quoted scalars,
+// literal (|) and folded (>) block scalars, and comments. Anchors, aliases, tags and multiple documents are not supported.
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// node is an ordered representation of a JSON value
+type node struct {
+	kind   nodeKind
+	scalar string // YAML representation of scalar nodes
+	keys   []string
+	values []*node
+}
+
+type nodeKind int
+
+const (
+	kindScalar nodeKind = iota
+	kindMap
+	kindSeq
+)
+
+// FromJSON converts JSON document into YAML, keeping order of object keys
+func FromJSON(b []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	n, err := readNode(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	switch {
+	case n.kind == kindMap && len(n.keys) > 0:
+		writeMap(&buf, n, 0, true)
+	case n.kind == kindSeq && len(n.values) > 0:
+		writeSeq(&buf, n, 0)
+	default:
+		buf.WriteString(inline(n))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// Marshal encodes v as YAML, by first encoding it as JSON
+func Marshal(v any) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return FromJSON(b)
+}
+
+func readNode(dec *json.Decoder) (*node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			n := &node{kind: kindMap}
+			for dec.More() {
+				kt, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, ok := kt.(string)
+				if !ok {
+					return nil, fmt.Errorf("yaml: unexpected object key %v", kt)
+				}
+				v, err := readNode(dec)
+				if err != nil {
+					return nil, err
+				}
+				n.keys = append(n.keys, key)
+				n.values = append(n.values, v)
+			}
+			_, err := dec.Token()
+			return n, err
+		case '[':
+			n := &node{kind: kindSeq}
+			for dec.More() {
+				v, err := readNode(dec)
+				if err != nil {
+					return nil, err
+				}
+				n.values = append(n.values, v)
+			}
+			_, err := dec.Token()
+			return n, err
+		}
+		return nil, fmt.Errorf("yaml: unexpected delimiter %v", t)
+	case nil:
+		return &node{kind: kindScalar, scalar: "null"}, nil
+	case bool:
+		return &node{kind: kindScalar, scalar: fmt.Sprint(t)}, nil
+	case json.Number:
+		return &node{kind: kindScalar, scalar: t.String()}, nil
+	case string:
+		return &node{kind: kindScalar, scalar: quote(t)}, nil
+	}
+
+	return nil, fmt.Errorf("yaml: unexpected token %v", tok)
+}
+
+var (
+	plainScalar    = regexp.MustCompile(`^[A-Za-z_/$][A-Za-z0-9_ ./$+()'-]*$`)
+	reservedScalar = regexp.MustCompile(`^(?i:true|false|yes|no|on|off|y|n|null|~)$`)
+)
+
+// quote returns string s as a YAML scalar, it is left as plain scalar when that can not be mistaken for anything else
+func quote(s string) string {
+	if plainScalar.MatchString(s) && !reservedScalar.MatchString(s) && strings.TrimSpace(s) == s {
+		return s
+	}
+
+	b, _ := json.Marshal(s)
+	// json escapes <, > and & as <, > and &, that YAML also understands
+	return string(b)
+}
+
+func inline(n *node) string {
+	switch n.kind {
+	case kindMap:
+		parts := make([]string, len(n.keys))
+		for i := range n.keys {
+			parts[i] = quote(n.keys[i]) + ": " + inline(n.values[i])
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	case kindSeq:
+		parts := make([]string, len(n.values))
+		for i := range n.values {
+			parts[i] = inline(n.values[i])
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	}
+	return n.scalar
+}
+
+func isBlock(n *node) bool {
+	return (n.kind == kindMap && len(n.keys) > 0) || (n.kind == kindSeq && len(n.values) > 0)
+}
+
+// writeMap writes mapping at indent, with padding of first line skipped when it follows a sequence entry `- `
+func writeMap(buf *bytes.Buffer, n *node, indent int, padFirst bool) {
+	for i := range n.keys {
+		if i > 0 || padFirst {
+			buf.WriteString(strings.Repeat(" ", indent))
+		}
+		buf.WriteString(quote(n.keys[i]))
+		buf.WriteByte(':')
+
+		v := n.values[i]
+		if !isBlock(v) {
+			buf.WriteByte(' ')
+			buf.WriteString(inline(v))
+			buf.WriteByte('\n')
+			continue
+		}
+
+		buf.WriteByte('\n')
+		if v.kind == kindMap {
+			writeMap(buf, v, indent+2, true)
+		} else {
+			writeSeq(buf, v, indent+2)
+		}
+	}
+}
+
+func writeSeq(buf *bytes.Buffer, n *node, indent int) {
+	for _, v := range n.values {
+		buf.WriteString(strings.Repeat(" ", indent))
+		buf.WriteByte('-')
+
+		switch {
+		case !isBlock(v):
+			buf.WriteByte(' ')
+			buf.WriteString(inline(v))
+			buf.WriteByte('\n')
+		case v.kind == kindMap:
+			buf.WriteByte(' ')
+			writeMap(buf, v, indent+2, false)
+		default:
+			buf.WriteByte('\n')
+			writeSeq(buf, v, indent+2)
+		}
+	}
+}
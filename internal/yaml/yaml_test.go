@@ -0,0 +1,148 @@
+package yaml
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestToJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{
+			name: "1. block mappings and sequences",
+			yaml: `
+openapi: 3.1.0 # version
+info:
+  title: "Pets: API"
+  version: '1.0'
+paths:
+  /pets/{id}:
+    get:
+      parameters:
+      - name: id
+        in: path
+        required: true
+        schema: {type: integer, minimum: 1}
+      - $ref: '#/components/parameters/Limit'
+      tags: [pets, "read"]
+`,
+			want: `{"openapi":"3.1.0","info":{"title":"Pets: API","version":"1.0"},"paths":{"/pets/{id}":{"get":{"parameters":[{"name":"id","in":"path","required":true,"schema":{"type":"integer","minimum":1}},{"$ref":"#/components/parameters/Limit"}],"tags":["pets","read"]}}}}`,
+		},
+		{
+			name: "2. scalars",
+			yaml: `
+a: ~
+b: yes
+c: 0x1f
+d: 1.5e3
+e: null
+f: http://example.com/#frag
+g: -
+h: "tab\tnew\nline é"
+i: 'it''s'
+`,
+			want: `{"a":null,"b":"yes","c":31,"d":1500,"e":null,"f":"http://example.com/#frag","g":"-","h":"tab\tnew\nline é","i":"it's"}`,
+		},
+		{
+			name: "3. block scalars",
+			yaml: `
+literal: |
+  line 1
+    indented # not a comment
+
+  line 3
+folded: >-
+  a
+  b
+
+  c
+keep: |+
+  x
+
+next: 1
+`,
+			want: `{"literal":"line 1\n  indented # not a comment\n\nline 3\n","folded":"a b\nc","keep":"x\n\n","next":1}`,
+		},
+		{
+			name: "4. nested sequences, and multiline flow",
+			yaml: `
+- - 1
+  - 2
+- [a,
+   b]
+- k: v
+  l:
+    - x
+-
+  m: n
+`,
+			want: `[[1,2],["a","b"],{"k":"v","l":["x"]},{"m":"n"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToJSON([]byte(tt.yaml))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var g, w any
+			json.Unmarshal(got, &g)
+			json.Unmarshal([]byte(tt.want), &w)
+			if !reflect.DeepEqual(g, w) {
+				t.Errorf("got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToJSON_Unsupported(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{name: "1. anchor", yaml: "base: &base\n  a: 1\n", wantErr: `yaml: line 1: anchor "&base" is not supported`},
+		{name: "2. alias", yaml: "base:\n  a: 1\nother: *base\n", wantErr: `yaml: line 3: alias "*base" is not supported`},
+		{name: "3. merge key", yaml: "other:\n  <<: x\n", wantErr: `yaml: line 2: merge key "<<" is not supported`},
+		{name: "4. tag", yaml: "- !!str 1\n", wantErr: `yaml: line 1: tag "!!str" is not supported`},
+		{name: "5. alias in flow collection", yaml: "list: [a, *b]\n", wantErr: `yaml: alias "*b" is not supported`},
+		{name: "6. tag on a document", yaml: "!custom\n", wantErr: `yaml: line 1: tag "!custom" is not supported`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ToJSON([]byte(tt.yaml))
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := `{"openapi":"3.1.0","info":{"title":"a: b","version":"1"},"list":[{"a":1,"b":[true,null,"yes"]},[],{}],"empty":"","n":-1.5,"multi":"x\ny"}`
+
+	y, err := FromJSON([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := ToJSON(y)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, y)
+	}
+
+	var a, b any
+	json.Unmarshal([]byte(in), &a)
+	json.Unmarshal(out, &b)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("round trip: got %s\nfrom yaml:\n%s", out, y)
+	}
+}
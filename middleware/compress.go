@@ -0,0 +1,390 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+// CompressWriter is a compressing writer. Writers of gzip, zlib, flate, brotli and zstd packages implement it
+type CompressWriter interface {
+	io.WriteCloser
+	Flush() error
+	// Reset discards writer's state, making it compress to w, so that writers can be reused
+	Reset(w io.Writer)
+}
+
+// Encoder creates CompressWriters, for a `Content-Encoding`
+//
+// Example (with github.com/andybalholm/brotli):
+//
+//	brotliEncoder := middleware.Encoder{
+//	    Encoding:  "br",
+//	    NewWriter: func(w io.Writer) middleware.CompressWriter { return brotli.NewWriter(w) },
+//	}
+type Encoder struct {
+	Encoding  string
+	NewWriter func(w io.Writer) CompressWriter
+}
+
+// GzipEncoder compresses with gzip, at level (like gzip.BestSpeed)
+func GzipEncoder(level int) Encoder {
+	return Encoder{
+		Encoding: "gzip",
+		NewWriter: func(w io.Writer) CompressWriter {
+			gw, err := gzip.NewWriterLevel(w, level)
+			if err != nil {
+				gw = gzip.NewWriter(w)
+			}
+			return gw
+		},
+	}
+}
+
+// DeflateEncoder compresses with deflate, at level (like flate.BestSpeed).
+// Output is in zlib format, which is what `Content-Encoding: deflate` means (RFC 9110)
+func DeflateEncoder(level int) Encoder {
+	return Encoder{
+		Encoding: "deflate",
+		NewWriter: func(w io.Writer) CompressWriter {
+			zw, err := zlib.NewWriterLevel(w, level)
+			if err != nil {
+				zw = zlib.NewWriter(w)
+			}
+			return zw
+		},
+	}
+}
+
+// DefaultCompressMinLength is the minimum size of response body, that gets compressed, when it is not set in CompressOptions
+const DefaultCompressMinLength = 1024
+
+// DefaultSkippedContentTypes are content types, that are already compressed
+var DefaultSkippedContentTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp", "image/avif",
+	"video/", "audio/", "font/woff",
+	"application/zip", "application/gzip", "application/x-gzip", "application/zstd", "application/x-7z-compressed", "application/x-rar-compressed",
+}
+
+// CompressOptions configures [Compress]
+type CompressOptions struct {
+	// Encoders in order of preference, they are picked as per request's `Accept-Encoding`.
+	// Defaults to gzip, followed by deflate. Other encodings (like brotli or zstd) are added here
+	Encoders []Encoder
+
+	// MinLength is the minimum size of response body, that gets compressed, defaults to [DefaultCompressMinLength].
+	// Flushed (i.e. streaming) responses are compressed regardless of their size
+	MinLength int
+
+	// SkipContentTypes are prefixes of content types, that are not compressed, defaults to [DefaultSkippedContentTypes]
+	SkipContentTypes []string
+}
+
+func (o *CompressOptions) withDefaultsIfMissing() {
+	if o.Encoders == nil {
+		o.Encoders = []Encoder{GzipEncoder(gzip.DefaultCompression), DeflateEncoder(flate.DefaultCompression)}
+	}
+	if o.MinLength <= 0 {
+		o.MinLength = DefaultCompressMinLength
+	}
+	if o.SkipContentTypes == nil {
+		o.SkipContentTypes = DefaultSkippedContentTypes
+	}
+}
+
+func (o *CompressOptions) skips(contentType string) bool {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	for _, prefix := range o.SkipContentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type pooledEncoder struct {
+	encoding string
+	pool     *sync.Pool
+}
+
+// Compress compresses response bodies with the encoding, that request's `Accept-Encoding` prefers (honouring q-values).
+//
+// Bodies smaller than MinLength, of content types in SkipContentTypes, partial content (206, or with `Content-Range`),
+// or already having a `Content-Encoding` are sent as is.
+// Compressed responses lose their `Content-Length`, and `Vary: Accept-Encoding` is set on all responses.
+//
+// Example:
+//
+//	r.Use(middleware.Compress())
+func Compress(compressOpts ...CompressOptions) ivy.Handler {
+	var opts CompressOptions
+	if len(compressOpts) > 0 {
+		opts = compressOpts[0]
+	}
+	opts.withDefaultsIfMissing()
+
+	encoders := make([]pooledEncoder, 0, len(opts.Encoders))
+	for _, enc := range opts.Encoders {
+		encoders = append(encoders, pooledEncoder{
+			encoding: enc.Encoding,
+			pool: &sync.Pool{New: func() any {
+				return enc.NewWriter(io.Discard)
+			}},
+		})
+	}
+
+	return func(c *ivy.Context) error {
+		c.ResponseWriter().Header().Add("Vary", "Accept-Encoding")
+
+		req := c.Request()
+		if req.Method == http.MethodHead {
+			return c.Next()
+		}
+
+		enc, ok := negotiateEncoding(req.Header.Get("Accept-Encoding"), encoders)
+		if !ok {
+			return c.Next()
+		}
+
+		original := c.ResponseWriter()
+		cw := &compressWriter{ResponseWriter: original, opts: &opts, enc: enc}
+		c.SetResponseWriter(ivy.NewResponseWriter(cw))
+
+		err := c.Next()
+
+		// INFO: a body buffered before an error is still sent, as it would be without Compress (ErrorHandler then sees a committed response)
+		if cerr := cw.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+
+		c.SetResponseWriter(original)
+		return err
+	}
+}
+
+// negotiateEncoding picks the encoder with the highest q-value in Accept-Encoding, ties are broken by order of encoders
+func negotiateEncoding(acceptEncoding string, encoders []pooledEncoder) (pooledEncoder, bool) {
+	if acceptEncoding == "" {
+		return pooledEncoder{}, false
+	}
+
+	qvalues := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			k, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(k, "q") {
+				if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					q = f
+				}
+			}
+		}
+		qvalues[name] = q
+	}
+
+	var best pooledEncoder
+	bestQ := 0.0
+	for _, enc := range encoders {
+		q, ok := qvalues[enc.encoding]
+		if !ok {
+			q = qvalues["*"]
+		}
+		if q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+// compressWriter buffers the start of response body, until it is big enough to be worth compressing (or is flushed),
+// and then decides whether to compress it, before writing headers
+type compressWriter struct {
+	http.ResponseWriter
+
+	opts *CompressOptions
+	enc  pooledEncoder
+
+	mu      sync.Mutex
+	status  int
+	buf     []byte
+	decided bool
+	cw      CompressWriter
+
+	closed   bool
+	hijacked bool
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.decided || w.status != 0 || w.closed {
+		return
+	}
+
+	// informational responses (like 103 Early Hints) go out right away
+	if code < 200 && code != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	w.status = code
+	if code == http.StatusNoContent || code == http.StatusNotModified || code == http.StatusSwitchingProtocols {
+		w.decide(false)
+	}
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	if w.decided {
+		return w.write(p)
+	}
+
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) < w.opts.MinLength {
+		return len(p), nil
+	}
+
+	if err := w.decide(true); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *compressWriter) write(p []byte) (int, error) {
+	if w.cw != nil {
+		return w.cw.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// decide writes headers, with Content-Encoding if the response is to be compressed, followed by the buffered body
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+
+	h := w.ResponseWriter.Header()
+	// INFO: net/http would sniff content type from the compressed body otherwise
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+
+	// INFO: partial content is a byte range of the original body, so it is never compressed
+	partial := w.status == http.StatusPartialContent || h.Get("Content-Range") != ""
+
+	if compress && !partial && h.Get("Content-Encoding") == "" && !w.opts.skips(h.Get("Content-Type")) {
+		h.Set("Content-Encoding", w.enc.encoding)
+		h.Del("Content-Length")
+		// byte ranges of the compressed body would not match ranges of the original one
+		h.Del("Accept-Ranges")
+
+		w.cw = w.enc.pool.Get().(CompressWriter)
+		w.cw.Reset(w.ResponseWriter)
+	}
+
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := w.write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// FlushError is what ivy.ResponseWriter (and http.ResponseController) calls to flush
+func (w *compressWriter) FlushError() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return io.ErrClosedPipe
+	}
+
+	// INFO: flushed responses are streams, whose parts may well stay below MinLength, so they are compressed regardless
+	if !w.decided {
+		if err := w.decide(true); err != nil {
+			return err
+		}
+	}
+	if w.cw != nil {
+		if err := w.cw.Flush(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	conn, brw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.hijacked = true
+		w.closed = true
+	}
+	return conn, brw, err
+}
+
+// Close writes the remaining body, and finishes compression
+func (w *compressWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if !w.decided {
+		if w.status == 0 && len(w.buf) == 0 {
+			// INFO: nothing has been written, net/http writes the implicit 200 itself
+			return nil
+		}
+		if err := w.decide(false); err != nil {
+			return err
+		}
+	}
+
+	if w.cw == nil {
+		return nil
+	}
+
+	err := w.cw.Close()
+	w.cw.Reset(io.Discard)
+	w.enc.pool.Put(w.cw)
+	w.cw = nil
+	return err
+}
+
+// Unwrap returns the underlying writer, so that http.ResponseController reaches it
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
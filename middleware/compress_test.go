@@ -0,0 +1,278 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+var largeJSON = `{"items": [` + strings.Repeat(`{"id": 1, "name": "item"},`, 100) + `{}]}`
+
+func TestCompress(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Use(Compress())
+	r.Get("/json", func(c *ivy.Context) error {
+		c.SetHeader("Content-Type", "application/json")
+		c.SetHeader("Content-Length", "9999")
+		return c.SendString(largeJSON)
+	})
+	r.Get("/small", func(c *ivy.Context) error { return c.SendString("hello") })
+	r.Get("/image", func(c *ivy.Context) error {
+		c.SetHeader("Content-Type", "image/png")
+		return c.SendString(largeJSON)
+	})
+	r.Get("/encoded", func(c *ivy.Context) error {
+		c.SetHeader("Content-Encoding", "gzip")
+		return c.SendString(largeJSON)
+	})
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		wantEncoding   string
+	}{
+		{name: "1. gzip", path: "/json", acceptEncoding: "gzip, deflate", wantEncoding: "gzip"},
+		{name: "2. preferred by q-value", path: "/json", acceptEncoding: "gzip;q=0.5, deflate", wantEncoding: "deflate"},
+		{name: "3. wildcard", path: "/json", acceptEncoding: "*", wantEncoding: "gzip"},
+		{name: "4. gzip excluded with q=0", path: "/json", acceptEncoding: "gzip;q=0, *;q=0.1", wantEncoding: "deflate"},
+		{name: "5. identity only", path: "/json", acceptEncoding: "identity", wantEncoding: ""},
+		{name: "6. no Accept-Encoding", path: "/json", acceptEncoding: "", wantEncoding: ""},
+		{name: "7. body below MinLength", path: "/small", acceptEncoding: "gzip", wantEncoding: ""},
+		{name: "8. already compressed content type", path: "/image", acceptEncoding: "gzip", wantEncoding: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Fatalf("content encoding: got %q, want %q", got, tt.wantEncoding)
+			}
+			if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("vary: got %q", got)
+			}
+
+			body := decompress(t, tt.wantEncoding, w.Body)
+			if tt.path == "/json" {
+				if body != largeJSON {
+					t.Errorf("unexpected body: %q", body)
+				}
+				if tt.wantEncoding != "" && w.Header().Get("Content-Length") != "" {
+					t.Errorf("Content-Length must be removed from compressed responses")
+				}
+			}
+		})
+	}
+
+	t.Run("9. body of HEAD request is not compressed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodHead, "/json", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Content-Encoding"); got != "" {
+			t.Errorf("content encoding: got %q", got)
+		}
+	})
+
+	t.Run("10. response with its own Content-Encoding", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/encoded", nil)
+		req.Header.Set("Accept-Encoding", "deflate")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Content-Encoding"); got != "gzip" || w.Body.String() != largeJSON {
+			t.Errorf("response must be sent as is, got encoding %q", got)
+		}
+	})
+}
+
+func decompress(t *testing.T, encoding string, body io.Reader) string {
+	t.Helper()
+
+	var r io.Reader
+	switch encoding {
+	case "gzip":
+		gr, err := gzip.NewReader(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = gr
+	case "deflate":
+		zr, err := zlib.NewReader(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = zr
+	default:
+		r = body
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestCompress_SSE(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	r := ivy.NewRouter()
+	r.Use(Compress())
+	r.Get("/events", func(c *ivy.Context) error {
+		stream := c.SSE(ivy.SSEOptions{Heartbeat: -1})
+		if err := stream.Send(ivy.Event{Data: "first"}); err != nil {
+			return err
+		}
+
+		// INFO: flushed event must be readable by the client, before the response is over
+		if got := decompress(t, "gzip", bytes.NewReader(w.Body.Bytes())); got != "data: first\n\n" {
+			t.Errorf("flushed event: got %q", got)
+		}
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("content encoding: got %q", got)
+	}
+	if !w.Flushed {
+		t.Errorf("response must have been flushed")
+	}
+}
+
+type upperWriter struct {
+	w io.Writer
+}
+
+func (u *upperWriter) Write(p []byte) (int, error) { return u.w.Write(bytes.ToUpper(p)) }
+func (u *upperWriter) Flush() error                { return nil }
+func (u *upperWriter) Close() error                { return nil }
+func (u *upperWriter) Reset(w io.Writer)           { u.w = w }
+
+func TestCompress_CustomEncoder(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Use(Compress(CompressOptions{
+		Encoders: []Encoder{
+			{Encoding: "upper", NewWriter: func(w io.Writer) CompressWriter { return &upperWriter{w: w} }},
+			GzipEncoder(gzip.BestSpeed),
+		},
+		MinLength: 1,
+	}))
+	r.Get("/", func(c *ivy.Context) error { return c.SendString("hello") })
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip, upper")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Content-Encoding"); got != "upper" {
+		t.Errorf("content encoding: got %q", got)
+	}
+	if got := w.Body.String(); got != "HELLO" {
+		t.Errorf("body: got %q", got)
+	}
+}
+
+func TestCompress_Error(t *testing.T) {
+	partial := func(c *ivy.Context) error {
+		c.SendString("partial")
+		return ivy.NewHTTPError(http.StatusBadRequest, "bad request")
+	}
+	failing := func(c *ivy.Context) error {
+		return ivy.NewHTTPError(http.StatusBadRequest, "bad request")
+	}
+
+	plain := ivy.NewRouter()
+	plain.Get("/partial", partial)
+	plain.Get("/failing", failing)
+
+	compressed := ivy.NewRouter()
+	compressed.Use(Compress())
+	compressed.Get("/partial", partial)
+	compressed.Get("/failing", failing)
+
+	sub := ivy.NewRouter()
+	sub.Get("/partial", partial)
+	sub.Get("/failing", failing)
+	mounted := ivy.NewRouter()
+	mounted.Use(Compress())
+	mounted.Mount("/sub", sub)
+
+	tests := []struct {
+		name     string
+		router   *ivy.Router
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "1. without Compress", router: plain, path: "/partial", wantCode: http.StatusOK, wantBody: "partial"},
+		{name: "2. with Compress", router: compressed, path: "/partial", wantCode: http.StatusOK, wantBody: "partial"},
+		{name: "3. mounted under Compress", router: mounted, path: "/sub/partial", wantCode: http.StatusOK, wantBody: "partial"},
+		{name: "4. nothing written, with Compress", router: compressed, path: "/failing", wantCode: http.StatusBadRequest, wantBody: "bad request\n"},
+		{name: "5. nothing written, mounted under Compress", router: mounted, path: "/sub/failing", wantCode: http.StatusBadRequest, wantBody: "bad request\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			w := httptest.NewRecorder()
+			tt.router.ServeHTTP(w, req)
+
+			// body written before the error is sent the same way, with or without Compress
+			if got := decompress(t, w.Header().Get("Content-Encoding"), w.Body); w.Code != tt.wantCode || got != tt.wantBody {
+				t.Errorf("got %d %q, want %d %q", w.Code, got, tt.wantCode, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCompress_PartialContent(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Use(Compress())
+	r.Get("/range", func(c *ivy.Context) error {
+		c.SetHeader("Content-Type", "application/json")
+		c.SetHeader("Content-Range", fmt.Sprintf("bytes 0-%d/%d", len(largeJSON)-1, len(largeJSON)+100))
+		return c.Status(http.StatusPartialContent).SendString(largeJSON)
+	})
+	r.Get("/file", func(c *ivy.Context) error {
+		http.ServeContent(c.ResponseWriter(), c.Request(), "items.json", time.Time{}, strings.NewReader(largeJSON))
+		return nil
+	})
+
+	for _, path := range []string{"/range", "/file"} {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			req.Header.Set("Range", "bytes=0-1999")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusPartialContent {
+				t.Fatalf("status: got %d", w.Code)
+			}
+			if got := w.Header().Get("Content-Encoding"); got != "" {
+				t.Errorf("partial content must not be compressed, got encoding %q", got)
+			}
+		})
+	}
+}
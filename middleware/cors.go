@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+// CORSOptions configures [CORS]
+type CORSOptions struct {
+	// AllowedOrigins are origins like "https://example.com", subdomain wildcards like "https://*.example.com", or "*" to allow any origin.
+	// "*" can not be used with AllowCredentials, as it would let every site make requests with the user's cookies
+	AllowedOrigins []string
+
+	// AllowOriginFunc allows origins, that are not in AllowedOrigins
+	AllowOriginFunc func(origin string, r *http.Request) bool
+
+	// AllowedMethods default to GET, HEAD, POST, PUT, PATCH and DELETE
+	AllowedMethods []string
+
+	// AllowedHeaders are request headers, that clients can send. When nil, headers asked for in preflight requests are allowed
+	AllowedHeaders []string
+
+	// ExposedHeaders are response headers, that clients can read, besides the CORS-safelisted ones
+	ExposedHeaders []string
+
+	// AllowCredentials allows requests with cookies, and HTTP auth
+	AllowCredentials bool
+
+	// MaxAge is how long browsers can cache preflight responses
+	MaxAge time.Duration
+}
+
+func (o *CORSOptions) withDefaultsIfMissing() {
+	if o.AllowedMethods == nil {
+		o.AllowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+}
+
+func (o *CORSOptions) isOriginAllowed(origin string, r *http.Request) bool {
+	for _, allowed := range o.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+
+		if prefix, suffix, ok := strings.Cut(allowed, "*"); ok {
+			origin := strings.ToLower(origin)
+			if len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, strings.ToLower(prefix)) && strings.HasSuffix(origin, strings.ToLower(suffix)) {
+				return true
+			}
+		}
+	}
+
+	return o.AllowOriginFunc != nil && o.AllowOriginFunc(origin, r)
+}
+
+func (o *CORSOptions) areHeadersAllowed(requested []string) bool {
+	if o.AllowedHeaders == nil {
+		return true
+	}
+
+	for _, h := range requested {
+		if !slices.ContainsFunc(o.AllowedHeaders, func(allowed string) bool { return allowed == "*" || strings.EqualFold(allowed, h) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// CORS implements [Cross-Origin Resource Sharing](https://fetch.spec.whatwg.org/#http-cors-protocol).
+//
+// Preflight requests are answered by it, with 204 No Content, without calling the next handlers.
+// It answers preflight requests even without any OPTIONS routes, as router's automatic OPTIONS (and 405) responses
+// run through middlewares of the router (or group) that the matched route belongs to, so it can be added on a group as well.
+//
+// Requests from origins, that are not allowed, get no CORS headers, so browsers block them.
+//
+// It panics, when AllowedOrigins has "*" along with AllowCredentials, allowed origins must then be listed, or checked with AllowOriginFunc.
+//
+// Example:
+//
+//	r.Use(middleware.CORS(middleware.CORSOptions{
+//	    AllowedOrigins:   []string{"https://app.example.com", "https://*.preview.example.com"},
+//	    AllowCredentials: true,
+//	    MaxAge:           time.Hour,
+//	}))
+func CORS(corsOpts ...CORSOptions) ivy.Handler {
+	var opts CORSOptions
+	if len(corsOpts) > 0 {
+		opts = corsOpts[0]
+	}
+	opts.withDefaultsIfMissing()
+
+	if opts.AllowCredentials && slices.Contains(opts.AllowedOrigins, "*") {
+		panic(`ivy/middleware: CORS can not allow "*" origin with AllowCredentials, list the allowed origins, or use AllowOriginFunc`)
+	}
+
+	allowAnyOrigin := slices.Contains(opts.AllowedOrigins, "*") && opts.AllowOriginFunc == nil
+
+	return func(c *ivy.Context) error {
+		req := c.Request()
+		h := c.ResponseWriter().Header()
+
+		preflight := req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != ""
+
+		// INFO: response depends on Origin (and preflight request headers), so caches must not serve it to other origins
+		if !allowAnyOrigin {
+			h.Add("Vary", "Origin")
+		}
+		if preflight {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		origin := req.Header.Get("Origin")
+		if origin == "" || !opts.isOriginAllowed(origin, req) {
+			if preflight {
+				return c.SendStatus(http.StatusNoContent)
+			}
+			return c.Next()
+		}
+
+		if allowAnyOrigin {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if opts.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if len(opts.ExposedHeaders) > 0 {
+				h.Set("Access-Control-Expose-Headers", strings.Join(opts.ExposedHeaders, ", "))
+			}
+			return c.Next()
+		}
+
+		method := req.Header.Get("Access-Control-Request-Method")
+		requestedHeaders := headerList(req.Header.Get("Access-Control-Request-Headers"))
+
+		if !slices.Contains(opts.AllowedMethods, method) || !opts.areHeadersAllowed(requestedHeaders) {
+			// INFO: browsers fail the preflight, as headers allowing the request are missing
+			h.Del("Access-Control-Allow-Origin")
+			h.Del("Access-Control-Allow-Credentials")
+			return c.SendStatus(http.StatusNoContent)
+		}
+
+		h.Set("Access-Control-Allow-Methods", strings.Join(opts.AllowedMethods, ", "))
+		if len(requestedHeaders) > 0 {
+			if opts.AllowedHeaders == nil {
+				h.Set("Access-Control-Allow-Headers", strings.Join(requestedHeaders, ", "))
+			} else {
+				h.Set("Access-Control-Allow-Headers", strings.Join(opts.AllowedHeaders, ", "))
+			}
+		}
+		if opts.MaxAge > 0 {
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(opts.MaxAge.Seconds())))
+		}
+
+		return c.SendStatus(http.StatusNoContent)
+	}
+}
+
+func headerList(v string) []string {
+	var list []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func TestCORS(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Use(CORS(CORSOptions{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.preview.example.com"},
+		AllowOriginFunc:  func(origin string, r *http.Request) bool { return origin == "http://localhost:3000" },
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		ExposedHeaders:   []string{"X-Total-Count"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}))
+	r.Get("/items", func(c *ivy.Context) error { return c.SendString("items") })
+	r.Post("/items", func(c *ivy.Context) error { return c.SendString("created") })
+
+	tests := []struct {
+		name        string
+		method      string
+		headers     map[string]string
+		wantStatus  int
+		wantHeaders map[string]string
+	}{
+		{
+			name:       "1. simple request from an allowed origin",
+			method:     http.MethodGet,
+			headers:    map[string]string{"Origin": "https://app.example.com"},
+			wantStatus: http.StatusOK,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Expose-Headers":    "X-Total-Count",
+				"Vary":                             "Origin",
+			},
+		},
+		{
+			name:        "2. wildcard subdomain",
+			method:      http.MethodGet,
+			headers:     map[string]string{"Origin": "https://pr-42.preview.example.com"},
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "https://pr-42.preview.example.com"},
+		},
+		{
+			name:        "3. origin allowed by predicate",
+			method:      http.MethodGet,
+			headers:     map[string]string{"Origin": "http://localhost:3000"},
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "http://localhost:3000"},
+		},
+		{
+			name:        "4. disallowed origin gets no CORS headers",
+			method:      http.MethodGet,
+			headers:     map[string]string{"Origin": "https://evil.com"},
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "", "Vary": "Origin"},
+		},
+		{
+			name:   "5. preflight without an OPTIONS route",
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "https://app.example.com",
+				"Access-Control-Request-Method":  "POST",
+				"Access-Control-Request-Headers": "content-type, authorization",
+			},
+			wantStatus: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "https://app.example.com",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "Content-Type, Authorization",
+				"Access-Control-Max-Age":       "600",
+			},
+		},
+		{
+			name:   "6. preflight with a disallowed method",
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "https://app.example.com",
+				"Access-Control-Request-Method": "DELETE",
+			},
+			wantStatus:  http.StatusNoContent,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Methods": ""},
+		},
+		{
+			name:   "7. preflight with a disallowed header",
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "https://app.example.com",
+				"Access-Control-Request-Method":  "POST",
+				"Access-Control-Request-Headers": "X-Secret",
+			},
+			wantStatus:  http.StatusNoContent,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/items", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status code: got %d, want %d", w.Code, tt.wantStatus)
+			}
+			for k, want := range tt.wantHeaders {
+				if got := strings.Join(w.Header().Values(k), ", "); !strings.HasPrefix(got, want) || (want == "" && got != "") {
+					t.Errorf("header %s: got %q, want %q", k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCORS_AnyOrigin(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Use(CORS(CORSOptions{AllowedOrigins: []string{"*"}}))
+	r.Get("/items", func(c *ivy.Context) error { return c.SendString("items") })
+
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("Origin", "https://anyone.com")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("allow origin: got %q", got)
+	}
+	if got := w.Header().Get("Vary"); got != "" {
+		t.Errorf("response does not vary by origin, got Vary %q", got)
+	}
+}
+
+func TestCORS_AnyOriginWithCredentials(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf(`CORS must panic for "*" origin with AllowCredentials`)
+		}
+	}()
+
+	CORS(CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+}
+
+func TestCORS_Group(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Get("/health", func(c *ivy.Context) error { return nil })
+	r.Group("/api", func(g *ivy.Router) {
+		g.Use(CORS(CORSOptions{AllowedOrigins: []string{"https://app.example.com"}}))
+		g.Post("/items", func(c *ivy.Context) error { return nil })
+	})
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/items", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Errorf("preflight of group route: got %d %v", w.Code, w.Header())
+	}
+
+	// routes outside the group do not get CORS headers
+	req = httptest.NewRequest(http.MethodOptions, "/health", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("route outside the group: got allow origin %q", got)
+	}
+}
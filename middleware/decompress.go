@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+// DefaultDecompressMaxSize is the maximum size of decompressed request bodies, when it is not set in DecompressOptions
+const DefaultDecompressMaxSize = 10 << 20
+
+// DecompressOptions configures [Decompress]
+type DecompressOptions struct {
+	// MaxSize is the maximum size of decompressed body (in bytes), defaults to [DefaultDecompressMaxSize].
+	// It is separate from router's MaxBodySize, which only limits the compressed body, as a few KiBs can decompress into GiBs
+	MaxSize int64
+}
+
+func (o *DecompressOptions) withDefaultsIfMissing() {
+	if o.MaxSize <= 0 {
+		o.MaxSize = DefaultDecompressMaxSize
+	}
+}
+
+// Decompress transparently decompresses request bodies with `Content-Encoding` gzip or deflate, for the next handlers.
+//
+// Reading past MaxSize of decompressed body fails with [*http.MaxBytesError], so Context.ParseBodyInto responds with 413 Request Entity Too Large.
+// Requests with other encodings get an [ivy.HTTPError] of 415 Unsupported Media Type, and malformed ones of 400 Bad Request,
+// which is what reading a truncated or corrupt body fails with as well.
+//
+// Example:
+//
+//	r.Use(middleware.Decompress(middleware.DecompressOptions{MaxSize: 50 << 20}))
+func Decompress(decompressOpts ...DecompressOptions) ivy.Handler {
+	var opts DecompressOptions
+	if len(decompressOpts) > 0 {
+		opts = decompressOpts[0]
+	}
+	opts.withDefaultsIfMissing()
+
+	return func(c *ivy.Context) error {
+		req := c.Request()
+
+		encodings := headerList(req.Header.Get("Content-Encoding"))
+		if len(encodings) == 0 || req.Body == nil || req.Body == http.NoBody {
+			return c.Next()
+		}
+
+		body := &decompressedBody{body: req.Body}
+		var r io.Reader = req.Body
+
+		// INFO: encodings are listed in the order they were applied, so they are undone in reverse
+		for i := len(encodings) - 1; i >= 0; i-- {
+			switch strings.ToLower(encodings[i]) {
+			case "gzip", "x-gzip":
+				gr, err := gzip.NewReader(r)
+				if err != nil {
+					return ivy.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("malformed gzip request body: %v", err))
+				}
+				body.closers = append(body.closers, gr)
+				r = gr
+			case "deflate":
+				dr, err := newDeflateReader(r)
+				if err != nil {
+					return ivy.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("malformed deflate request body: %v", err))
+				}
+				body.closers = append(body.closers, dr)
+				r = dr
+			case "identity":
+			default:
+				c.ResponseWriter().Header().Set("Accept-Encoding", "gzip, deflate")
+				return ivy.NewHTTPError(http.StatusUnsupportedMediaType, fmt.Sprintf("unsupported content encoding %q", encodings[i]))
+			}
+		}
+
+		body.r = r
+		body.remaining = opts.MaxSize
+		body.limit = opts.MaxSize
+
+		req.Body = body
+		req.ContentLength = -1
+		req.Header.Del("Content-Encoding")
+		req.Header.Del("Content-Length")
+
+		return c.Next()
+	}
+}
+
+// newDeflateReader reads deflate encoded r, which is zlib format (RFC 1950), as per RFC 9110.
+// INFO: some clients send raw DEFLATE data (RFC 1951) instead, so it is read as that, when r does not start with a zlib header
+func newDeflateReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	if header, err := br.Peek(2); err == nil && isZlibHeader(header) {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// isZlibHeader tells whether header is a valid zlib header, with DEFLATE compression method and a correct check value
+func isZlibHeader(header []byte) bool {
+	return header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0
+}
+
+// decompressedBody reads at most limit bytes of decompressed body, like http.MaxBytesReader
+type decompressedBody struct {
+	r       io.Reader
+	body    io.ReadCloser
+	closers []io.Closer
+
+	limit     int64
+	remaining int64
+	err       error
+}
+
+func (d *decompressedBody) Read(p []byte) (int, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+
+	// INFO: reading a byte more than remaining, tells whether the body goes past the limit
+	if int64(len(p)) > d.remaining+1 {
+		p = p[:d.remaining+1]
+	}
+
+	n, err := d.r.Read(p)
+	if isCorrupt(err) {
+		err = ivy.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("malformed compressed request body: %v", err))
+	}
+
+	if int64(n) <= d.remaining {
+		d.remaining -= int64(n)
+		d.err = err
+		return n, err
+	}
+
+	n = int(d.remaining)
+	d.remaining = 0
+	d.err = &http.MaxBytesError{Limit: d.limit}
+	return n, d.err
+}
+
+// isCorrupt tells whether err is from a truncated or corrupt compressed body
+func isCorrupt(err error) bool {
+	var corruptInput flate.CorruptInputError
+	return errors.Is(err, gzip.ErrChecksum) || errors.Is(err, gzip.ErrHeader) || errors.Is(err, zlib.ErrChecksum) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &corruptInput)
+}
+
+func (d *decompressedBody) Close() error {
+	for _, c := range d.closers {
+		c.Close()
+	}
+	return d.body.Close()
+}
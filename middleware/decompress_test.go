@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func TestDecompress(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Use(Decompress(DecompressOptions{MaxSize: 1 << 10}))
+	r.Post("/", func(c *ivy.Context) error {
+		var p struct {
+			Name string `json:"name"`
+		}
+		if err := c.ParseBodyInto(&p); err != nil {
+			return err
+		}
+		return c.SendString(p.Name)
+	})
+
+	gzipped := func(s string) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(s))
+		zw.Close()
+		return buf.Bytes()
+	}
+	deflated := func(s string) []byte {
+		var buf bytes.Buffer
+		zw, _ := zlib.NewWriterLevel(&buf, flate.BestCompression)
+		zw.Write([]byte(s))
+		zw.Close()
+		return buf.Bytes()
+	}
+	rawDeflated := func(s string) []byte {
+		var buf bytes.Buffer
+		fw, _ := flate.NewWriter(&buf, flate.BestCompression)
+		fw.Write([]byte(s))
+		fw.Close()
+		return buf.Bytes()
+	}
+
+	truncated := gzipped(`{"name": "ivy"}`)
+	truncated = truncated[:len(truncated)-6]
+
+	corrupt := gzipped(`{"name": "ivy"}`)
+	corrupt[len(corrupt)-8] ^= 0xff // checksum
+
+	corruptDeflate := deflated(`{"name": "ivy"}`)
+	corruptDeflate[len(corruptDeflate)-1] ^= 0xff // checksum
+
+	// a few bytes of gzip, that decompress way past MaxSize
+	bomb := gzipped(`{"name": "` + strings.Repeat("a", 1<<20) + `"}`)
+
+	tests := []struct {
+		name       string
+		encoding   string
+		body       []byte
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "1. gzip", encoding: "gzip", body: gzipped(`{"name": "ivy"}`), wantStatus: http.StatusOK, wantBody: "ivy"},
+		{name: "2. deflate", encoding: "deflate", body: deflated(`{"name": "ivy"}`), wantStatus: http.StatusOK, wantBody: "ivy"},
+		{name: "3. not encoded", encoding: "", body: []byte(`{"name": "ivy"}`), wantStatus: http.StatusOK, wantBody: "ivy"},
+		{name: "4. decompressed body past MaxSize", encoding: "gzip", body: bomb, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "5. unsupported encoding", encoding: "br", body: []byte("..."), wantStatus: http.StatusUnsupportedMediaType},
+		{name: "6. malformed gzip", encoding: "gzip", body: []byte("not gzip"), wantStatus: http.StatusBadRequest},
+		{name: "7. truncated gzip", encoding: "gzip", body: truncated, wantStatus: http.StatusBadRequest},
+		{name: "8. gzip with bad checksum", encoding: "gzip", body: corrupt, wantStatus: http.StatusBadRequest},
+		{name: "9. corrupt deflate", encoding: "deflate", body: []byte{0xff, 0xff, 0xff, 0xff}, wantStatus: http.StatusBadRequest},
+		{name: "10. raw deflate", encoding: "deflate", body: rawDeflated(`{"name": "ivy"}`), wantStatus: http.StatusOK, wantBody: "ivy"},
+		{name: "11. deflate with bad checksum", encoding: "deflate", body: corruptDeflate, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body))
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got %d (%s), want %d", w.Code, strings.TrimSpace(w.Body.String()), tt.wantStatus)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body: got %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
@@ -2,11 +2,11 @@ package middleware
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/nxtcoder17/ivy"
-	"github.com/nxtcoder17/ivy/middleware/internal/logger"
 )
 
 type LoggerOptions struct {
@@ -52,18 +52,22 @@ func Logger(loggerOpts ...LoggerOptions) ivy.Handler {
 
 		start := time.Now()
 
-		rw := &logger.ResponseWriter{
-			StatusCode: 0,
-			HttpRW:     c.ResponseWriter(),
-		}
-
-		c.SetResponseWriter(rw)
-
 		c.Logger.Debug(fmt.Sprintf("❯❯ %s %s", c.Request().Method, route))
-		defer func() {
-			c.Logger.Info(fmt.Sprintf("❮❮ %d %s %s took %s", rw.StatusCode, c.Request().Method, route, time.Since(start).String()))
-		}()
+
+		// INFO: logged once ErrorHandler has written the response, so that status code is the one sent
+		c.AfterResponse(func() {
+			c.Logger.Info(fmt.Sprintf("❮❮ %d %s %s (%d bytes) took %s", sentStatus(c), c.Request().Method, route, c.BytesWritten(), time.Since(start).String()))
+		})
 
 		return c.Next()
 	}
 }
+
+// sentStatus is the status code of the response, once it is done
+func sentStatus(c *ivy.Context) int {
+	if !c.Committed() {
+		// net/http responds with 200, when nothing has been written
+		return http.StatusOK
+	}
+	return c.StatusCode()
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func TestLogger(t *testing.T) {
+	var logs bytes.Buffer
+	defer func(l *slog.Logger) { ivy.Logger = l }(ivy.Logger)
+	ivy.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	r := ivy.NewRouter()
+	r.ErrorHandler = func(c *ivy.Context, err error) {
+		c.Status(http.StatusTeapot).SendString("teapot")
+	}
+	r.Use(Logger())
+	r.Get("/ok", func(c *ivy.Context) error { return c.SendString("hello") })
+	r.Get("/empty", func(c *ivy.Context) error { return nil })
+	r.Get("/fail", func(c *ivy.Context) error { return errors.New("failed") })
+
+	tests := map[string]string{
+		"/ok":    "❮❮ 200 GET /ok (5 bytes)",
+		"/empty": "❮❮ 200 GET /empty (0 bytes)",
+		"/fail":  "❮❮ 418 GET /fail (6 bytes)",
+	}
+
+	for path, want := range tests {
+		logs.Reset()
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("%s: expected log line with %q, got %s", path, want, logs.String())
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+// MetricsOptions configures [Metrics]
+type MetricsOptions struct {
+	// Namespace is prepended to metric names, like `<namespace>_http_requests_total`
+	Namespace string
+
+	// DurationBuckets are upper bounds (in seconds) of request duration histogram buckets, default to [DefaultDurationBuckets]
+	DurationBuckets []float64
+
+	// SizeBuckets are upper bounds (in bytes) of response size histogram buckets, default to [DefaultSizeBuckets]
+	SizeBuckets []float64
+}
+
+var (
+	DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	DefaultSizeBuckets     = []float64{100, 1000, 10_000, 100_000, 1_000_000, 10_000_000}
+)
+
+func (o *MetricsOptions) withDefaultsIfMissing() {
+	if o.DurationBuckets == nil {
+		o.DurationBuckets = DefaultDurationBuckets
+	}
+	if o.SizeBuckets == nil {
+		o.SizeBuckets = DefaultSizeBuckets
+	}
+}
+
+// Metrics records request counts, in-flight requests, request durations and response sizes,
+// and serves them in [Prometheus text format](https://prometheus.io/docs/instrumenting/exposition_formats/).
+//
+// Requests are labelled by method, status and route pattern (see [ivy.Context.RoutePattern]), instead of the request path,
+// so that path params do not create a time series per value. Requests, that no route matched, are labelled as `route="unmatched"`,
+// and ones with a non-standard method as `method="other"`.
+//
+// Example:
+//
+//	metrics := middleware.NewMetrics()
+//	r.Use(metrics.Middleware())
+//	r.Get("/metrics", metrics.Handler())
+type Metrics struct {
+	opts MetricsOptions
+
+	mu       sync.Mutex
+	requests map[string]uint64
+	inFlight map[string]int64
+	duration map[string]*histogram
+	size     map[string]*histogram
+}
+
+// NewMetrics creates a Metrics, with no recorded requests
+func NewMetrics(opts ...MetricsOptions) *Metrics {
+	var opt MetricsOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	opt.withDefaultsIfMissing()
+
+	return &Metrics{
+		opts:     opt,
+		requests: map[string]uint64{},
+		inFlight: map[string]int64{},
+		duration: map[string]*histogram{},
+		size:     map[string]*histogram{},
+	}
+}
+
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func (h *histogram) observe(v float64) {
+	for i, le := range h.buckets {
+		if v <= le {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// Middleware records metrics of requests, that pass through it
+func (m *Metrics) Middleware() ivy.Handler {
+	return func(c *ivy.Context) error {
+		method := methodLabel(c.Request().Method)
+		inFlight := labels("method", method)
+
+		m.mu.Lock()
+		m.inFlight[inFlight]++
+		m.mu.Unlock()
+
+		// INFO: deferred, so that panicking handlers (like ones aborting with http.ErrAbortHandler) are no longer counted as in flight
+		defer func() {
+			m.mu.Lock()
+			m.inFlight[inFlight]--
+			m.mu.Unlock()
+		}()
+
+		start := time.Now()
+		err := c.Next()
+
+		// INFO: recorded once ErrorHandler has responded, so that status and body size of error responses are counted
+		c.AfterResponse(func() {
+			elapsed := time.Since(start)
+
+			// INFO: pattern is read after c.Next(), as routes of mounted routers are matched further down the chain
+			route := c.RoutePattern()
+			if _, path, ok := strings.Cut(route, " "); ok {
+				route = strings.TrimLeft(path, " ")
+			}
+			if route == "" {
+				route = "unmatched"
+			}
+
+			status := strconv.Itoa(sentStatus(c))
+
+			m.mu.Lock()
+			defer m.mu.Unlock()
+
+			m.requests[labels("method", method, "route", route, "status", status)]++
+			m.observe(m.duration, m.opts.DurationBuckets, labels("method", method, "route", route, "status", status), elapsed.Seconds())
+			m.observe(m.size, m.opts.SizeBuckets, labels("method", method, "route", route), float64(c.BytesWritten()))
+		})
+
+		return err
+	}
+}
+
+// methodLabel is method, when it is a standard one, or else "other", as clients can send any method
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "other"
+}
+
+func (m *Metrics) observe(series map[string]*histogram, buckets []float64, key string, v float64) {
+	h, ok := series[key]
+	if !ok {
+		h = &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+		series[key] = h
+	}
+	h.observe(v)
+}
+
+// labels formats label pairs, like `method="GET",route="/"`, which is used as key of a time series as well
+func labels(kv ...string) string {
+	var sb strings.Builder
+	for i := 0; i < len(kv); i += 2 {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(kv[i])
+		sb.WriteString(`="`)
+		sb.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(kv[i+1]))
+		sb.WriteByte('"')
+	}
+	return sb.String()
+}
+
+// Handler serves the recorded metrics in Prometheus text exposition format
+func (m *Metrics) Handler() ivy.Handler {
+	return func(c *ivy.Context) error {
+		c.SetHeader("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		return c.SendString(m.String())
+	}
+}
+
+// String returns the recorded metrics in Prometheus text exposition format
+func (m *Metrics) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name := func(s string) string {
+		if m.opts.Namespace == "" {
+			return s
+		}
+		return m.opts.Namespace + "_" + s
+	}
+
+	var sb strings.Builder
+
+	writeHeader(&sb, name("http_requests_total"), "counter", "Total number of HTTP requests.")
+	for _, key := range sortedKeys(m.requests) {
+		fmt.Fprintf(&sb, "%s{%s} %d\n", name("http_requests_total"), key, m.requests[key])
+	}
+
+	writeHeader(&sb, name("http_requests_in_flight"), "gauge", "Number of HTTP requests being served.")
+	for _, key := range sortedKeys(m.inFlight) {
+		fmt.Fprintf(&sb, "%s{%s} %d\n", name("http_requests_in_flight"), key, m.inFlight[key])
+	}
+
+	writeHeader(&sb, name("http_request_duration_seconds"), "histogram", "Duration of HTTP requests in seconds.")
+	writeHistograms(&sb, name("http_request_duration_seconds"), m.duration)
+
+	writeHeader(&sb, name("http_response_size_bytes"), "histogram", "Size of HTTP response bodies in bytes.")
+	writeHistograms(&sb, name("http_response_size_bytes"), m.size)
+
+	return sb.String()
+}
+
+func writeHeader(sb *strings.Builder, name, typ, help string) {
+	fmt.Fprintf(sb, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func writeHistograms(sb *strings.Builder, name string, series map[string]*histogram) {
+	for _, key := range sortedKeys(series) {
+		h := series[key]
+		for i, le := range h.buckets {
+			fmt.Fprintf(sb, "%s_bucket{%s,le=%q} %d\n", name, key, formatFloat(le), h.counts[i])
+		}
+		fmt.Fprintf(sb, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, key, h.count)
+		fmt.Fprintf(sb, "%s_sum{%s} %s\n", name, key, formatFloat(h.sum))
+		fmt.Fprintf(sb, "%s_count{%s} %d\n", name, key, h.count)
+	}
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
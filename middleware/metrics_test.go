@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics(MetricsOptions{DurationBuckets: []float64{1}, SizeBuckets: []float64{8}})
+
+	r := ivy.NewRouter()
+	r.Use(metrics.Middleware())
+	r.Get("/metrics", metrics.Handler())
+	r.Get("/users/{id}", func(c *ivy.Context) error {
+		if c.PathParam("id") == "0" {
+			return ivy.NewHTTPError(http.StatusNotFound, "no such user")
+		}
+		return c.SendString("user " + c.PathParam("id"))
+	})
+
+	v2 := ivy.NewRouter()
+	v2.Get("/items/{id}", func(c *ivy.Context) error { return nil })
+	r.Mount("/v2", v2)
+
+	for _, path := range []string{"/users/1", "/users/2", "/users/0", "/v2/items/1", "/nope"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("content type: got %q", ct)
+	}
+
+	for _, want := range []string{
+		"# TYPE http_requests_total counter",
+		`http_requests_total{method="GET",route="/users/{id}",status="200"} 2`,
+		`http_requests_total{method="GET",route="/users/{id}",status="404"} 1`,
+		`http_requests_total{method="GET",route="/v2/items/{id}",status="200"} 1`,
+		`http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`http_requests_in_flight{method="GET"} 1`,
+		"# TYPE http_request_duration_seconds histogram",
+		`http_request_duration_seconds_bucket{method="GET",route="/users/{id}",status="200",le="1"} 2`,
+		`http_request_duration_seconds_count{method="GET",route="/users/{id}",status="200"} 2`,
+		// "user 1" and "user 2" are in the first bucket, and the error response "no such user\n" is counted as well
+		`http_response_size_bytes_bucket{method="GET",route="/users/{id}",le="8"} 2`,
+		`http_response_size_bytes_bucket{method="GET",route="/users/{id}",le="+Inf"} 3`,
+		`http_response_size_bytes_sum{method="GET",route="/users/{id}"} 25`,
+	} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("%q not found in:\n%s", want, w.Body.String())
+		}
+	}
+}
+
+func TestMetrics_Panic(t *testing.T) {
+	metrics := NewMetrics()
+
+	r := ivy.NewRouter()
+	r.Use(metrics.Middleware())
+	r.Get("/abort", func(c *ivy.Context) error { panic(http.ErrAbortHandler) })
+
+	func() {
+		defer func() { recover() }()
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	}()
+
+	if want := `http_requests_in_flight{method="GET"} 0`; !strings.Contains(metrics.String(), want) {
+		t.Errorf("%q not found in:\n%s", want, metrics.String())
+	}
+}
+
+func TestMetrics_NonStandardMethods(t *testing.T) {
+	metrics := NewMetrics()
+
+	r := ivy.NewRouter()
+	r.Use(metrics.Middleware())
+	r.Get("/users", func(c *ivy.Context) error { return c.SendString("users") })
+
+	for _, method := range []string{"FOO", "BAR", "get"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/users", nil))
+	}
+
+	got := metrics.String()
+	for _, want := range []string{
+		`http_requests_total{method="other",route="unmatched",status="405"} 3`,
+		`http_requests_in_flight{method="other"} 0`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q not found in:\n%s", want, got)
+		}
+	}
+	for _, method := range []string{"FOO", "BAR", "get"} {
+		if strings.Contains(got, `method="`+method+`"`) {
+			t.Errorf("method %q must not be a label value, got:\n%s", method, got)
+		}
+	}
+}
@@ -0,0 +1,525 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nxtcoder17/ivy"
+	"github.com/nxtcoder17/ivy/internal/yaml"
+)
+
+type OpenAPIOptions struct {
+	// ValidateResponses also validates status code and JSON body of responses against the spec.
+	// Responses are buffered until they are validated, so it is meant to be used in tests.
+	// A response, that does not match the spec, is replaced with an [ivy.HTTPError] with status code 500
+	ValidateResponses bool
+
+	// RejectUnknownRoutes responds with 404 (or 405), for requests that match no operation in the spec.
+	// By default, such requests are passed through without validation
+	RejectUnknownRoutes bool
+}
+
+// OpenAPIValidator validates requests against an OpenAPI (3.0 or 3.1) spec, in JSON or YAML.
+//
+// Incoming request is matched to an operation in the spec, and its path, query, header and cookie params,
+// along with its JSON body are validated against their schemas.
+// Failures are returned as an [ivy.HTTPError] with status code 400, with an [ivy.FieldError] for each of them, so that router's ErrorHandler can respond with them.
+//
+// Only local references (like `#/components/schemas/Pet`) are supported in the spec.
+// YAML specs are read with a small builtin parser, which does not support anchors (`&name`), aliases (`*name`), merge keys (`<<`)
+// and tags (`!name`), an error naming them is returned instead. Such specs must be converted to JSON first.
+//
+// Example:
+//
+//	validator, err := middleware.OpenAPIValidator(spec)
+//	if err != nil {
+//	    return err
+//	}
+//	r.Use(validator)
+func OpenAPIValidator(spec []byte, opts ...OpenAPIOptions) (ivy.Handler, error) {
+	var opt OpenAPIOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	doc, err := loadSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *ivy.Context) error {
+		op, pathValues, err := doc.match(c.Request())
+		if err != nil {
+			if opt.RejectUnknownRoutes {
+				return err
+			}
+			return c.Next()
+		}
+
+		if err := doc.validateRequest(op, pathValues, c.Request()); err != nil {
+			return err
+		}
+
+		if !opt.ValidateResponses {
+			return c.Next()
+		}
+
+		return doc.validateResponse(op, c)
+	}, nil
+}
+
+// OpenAPIValidatorFS is [OpenAPIValidator], with spec read from file `name` in fsys.
+// As with OpenAPIValidator, YAML specs must not use anchors, aliases, merge keys or tags.
+func OpenAPIValidatorFS(fsys fs.FS, name string, opts ...OpenAPIOptions) (ivy.Handler, error) {
+	spec, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return OpenAPIValidator(spec, opts...)
+}
+
+type openAPISpec struct {
+	schemas    *schemaValidator
+	basePath   string
+	operations []*specOperation
+}
+
+type specOperation struct {
+	method  string
+	path    string
+	pattern *regexp.Regexp
+	// params are the names of path template expressions, in order of the capture groups in pattern
+	params []string
+	// templates is the count of path template expressions, operations with fewer of them are matched first
+	templates int
+
+	parameters  []map[string]any
+	requestBody map[string]any
+	responses   map[string]any
+}
+
+func loadSpec(spec []byte) (*openAPISpec, error) {
+	trimmed := bytes.TrimSpace(spec)
+	if len(trimmed) > 0 && trimmed[0] != '{' {
+		b, err := yaml.ToJSON(spec)
+		if err != nil {
+			return nil, fmt.Errorf("openapi: %w", err)
+		}
+		spec = b
+	}
+
+	var root map[string]any
+	if err := json.Unmarshal(spec, &root); err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+
+	version, _ := root["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("openapi: unsupported spec version %q, only 3.x is supported", version)
+	}
+
+	doc := &openAPISpec{schemas: &schemaValidator{root: root}}
+
+	// INFO: path prefix of the first server (like `/v1` from `https://api.example.com/v1`), is where paths are served from
+	if servers, _ := root["servers"].([]any); len(servers) > 0 {
+		if server, ok := servers[0].(map[string]any); ok {
+			if u, err := url.Parse(fmt.Sprint(server["url"])); err == nil {
+				doc.basePath = strings.TrimSuffix(u.Path, "/")
+			}
+		}
+	}
+
+	paths, _ := root["paths"].(map[string]any)
+	for path, item := range paths {
+		pathItem, err := doc.schemas.resolve(item)
+		if err != nil {
+			return nil, err
+		}
+
+		pattern, params, err := compilePathTemplate(doc.basePath + path)
+		if err != nil {
+			return nil, err
+		}
+
+		common, err := doc.parameters(pathItem["parameters"], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for key, v := range pathItem {
+			method := strings.ToUpper(key)
+			operation, ok := v.(map[string]any)
+			if !ok || !slices.Contains([]string{"GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH", "TRACE"}, method) {
+				continue
+			}
+
+			op := &specOperation{method: method, path: path, pattern: pattern, params: params, templates: len(params)}
+			if op.parameters, err = doc.parameters(operation["parameters"], common); err != nil {
+				return nil, err
+			}
+
+			if rb, ok := operation["requestBody"]; ok {
+				if op.requestBody, err = doc.schemas.resolve(rb); err != nil {
+					return nil, err
+				}
+			}
+
+			op.responses, _ = operation["responses"].(map[string]any)
+			doc.operations = append(doc.operations, op)
+		}
+	}
+
+	// INFO: `/pets/mine` must be matched before `/pets/{id}`
+	slices.SortStableFunc(doc.operations, func(a, b *specOperation) int {
+		if a.templates != b.templates {
+			return a.templates - b.templates
+		}
+		return strings.Compare(a.path, b.path)
+	})
+
+	return doc, nil
+}
+
+var pathTemplate = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// compilePathTemplate compiles path like `/pets/{id}` into a regular expression, and returns names of its template expressions
+func compilePathTemplate(path string) (*regexp.Regexp, []string, error) {
+	var params []string
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	last := 0
+	for _, m := range pathTemplate.FindAllStringSubmatchIndex(path, -1) {
+		sb.WriteString(regexp.QuoteMeta(path[last:m[0]]))
+		sb.WriteString("([^/]+)")
+		params = append(params, path[m[2]:m[3]])
+		last = m[1]
+	}
+	sb.WriteString(regexp.QuoteMeta(path[last:]))
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("openapi: invalid path %q: %w", path, err)
+	}
+	return re, params, nil
+}
+
+// parameters resolves parameter list, operation parameters override path item parameters with the same name and location
+func (doc *openAPISpec) parameters(v any, common []map[string]any) ([]map[string]any, error) {
+	list, _ := v.([]any)
+
+	params := slices.Clone(common)
+	for _, item := range list {
+		param, err := doc.schemas.resolve(item)
+		if err != nil {
+			return nil, err
+		}
+
+		params = slices.DeleteFunc(params, func(p map[string]any) bool {
+			return p["name"] == param["name"] && p["in"] == param["in"]
+		})
+		params = append(params, param)
+	}
+
+	return params, nil
+}
+
+func (doc *openAPISpec) match(req *http.Request) (*specOperation, map[string]string, error) {
+	path := req.URL.EscapedPath()
+
+	pathMatched := false
+	for _, op := range doc.operations {
+		m := op.pattern.FindStringSubmatch(path)
+		if m == nil {
+			continue
+		}
+
+		pathMatched = true
+		if op.method != req.Method && (op.method != http.MethodGet || req.Method != http.MethodHead) {
+			continue
+		}
+
+		values := make(map[string]string, len(op.params))
+		for i, name := range op.params {
+			v, err := url.PathUnescape(m[i+1])
+			if err != nil {
+				v = m[i+1]
+			}
+			values[name] = v
+		}
+		return op, values, nil
+	}
+
+	if pathMatched {
+		return nil, nil, ivy.NewHTTPError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+	}
+	return nil, nil, ivy.NewHTTPError(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+}
+
+func (doc *openAPISpec) validateRequest(op *specOperation, pathValues map[string]string, req *http.Request) error {
+	var errs []error
+
+	query := req.URL.Query()
+
+	for _, param := range op.parameters {
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		required, _ := param["required"].(bool)
+
+		var values []string
+		switch in {
+		case "path":
+			if v, ok := pathValues[name]; ok {
+				values = []string{v}
+			}
+			required = true
+		case "query":
+			values = query[name]
+		case "header":
+			values = req.Header.Values(name)
+		case "cookie":
+			if cookie, err := req.Cookie(name); err == nil {
+				values = []string{cookie.Value}
+			}
+		default:
+			continue
+		}
+
+		if len(values) == 0 {
+			if required {
+				errs = append(errs, &ivy.FieldError{Field: name, Source: in, Err: errors.New("is required")})
+			}
+			continue
+		}
+
+		schema, err := doc.schemas.resolve(param["schema"])
+		if err != nil {
+			return err
+		}
+
+		explode := in == "query"
+		if v, ok := param["explode"].(bool); ok {
+			explode = v
+		}
+
+		v, err := doc.schemas.parseParam(schema, values, explode)
+		if err != nil {
+			errs = append(errs, &ivy.FieldError{Field: name, Source: in, Err: err})
+			continue
+		}
+
+		var fieldErrs []schemaError
+		doc.schemas.validate(schema, v, "", directionRequest, &fieldErrs)
+		for _, fe := range fieldErrs {
+			errs = append(errs, &ivy.FieldError{Field: name + fe.path, Source: in, Err: errors.New(fe.message)})
+		}
+	}
+
+	bodyErrs, err := doc.validateRequestBody(op, req)
+	if err != nil {
+		return err
+	}
+	errs = append(errs, bodyErrs...)
+
+	if len(errs) > 0 {
+		return ivy.NewHTTPErrors(http.StatusBadRequest, "request does not match OpenAPI spec", errs...)
+	}
+
+	return nil
+}
+
+func (doc *openAPISpec) validateRequestBody(op *specOperation, req *http.Request) ([]error, error) {
+	if op.requestBody == nil {
+		return nil, nil
+	}
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		// INFO: body is read for validation, so handlers get a copy of it
+		req.Body = io.NopCloser(bytes.NewReader(b))
+		body = b
+	}
+
+	if len(body) == 0 {
+		if required, _ := op.requestBody["required"].(bool); required {
+			return []error{&ivy.FieldError{Field: "$", Source: "body", Err: errors.New("is required")}}, nil
+		}
+		return nil, nil
+	}
+
+	content, _ := op.requestBody["content"].(map[string]any)
+	contentType := req.Header.Get("Content-Type")
+	mediaType, schema, ok := lookupContent(content, contentType)
+	if !ok {
+		return nil, ivy.NewHTTPError(http.StatusUnsupportedMediaType, fmt.Sprintf("unsupported content type %q", contentType))
+	}
+
+	return doc.validateBody(schema, mediaType, body, "body", directionRequest)
+}
+
+// validateBody validates JSON body against schema, bodies of other media types are not validated
+func (doc *openAPISpec) validateBody(schema any, mediaType string, body []byte, source string, dir direction) ([]error, error) {
+	if schema == nil || !isJSON(mediaType) {
+		return nil, nil
+	}
+
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return []error{&ivy.FieldError{Field: "$", Source: source, Err: fmt.Errorf("invalid JSON: %w", err)}}, nil
+	}
+
+	var fieldErrs []schemaError
+	doc.schemas.validate(schema, v, "", dir, &fieldErrs)
+
+	errs := make([]error, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		field := strings.TrimPrefix(fe.path, ".")
+		if field == "" {
+			field = "$"
+		}
+		errs = append(errs, &ivy.FieldError{Field: field, Source: source, Err: errors.New(fe.message)})
+	}
+	return errs, nil
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// lookupContent finds media type object for contentType, in content map of a request body or response.
+// Media ranges like `application/*` and `*/*` in the spec are matched too
+func lookupContent(content map[string]any, contentType string) (string, any, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "application/json"
+	}
+
+	kind, _, _ := strings.Cut(mediaType, "/")
+	for _, key := range []string{mediaType, kind + "/*", "*/*"} {
+		for k, v := range content {
+			if mt, _, _ := mime.ParseMediaType(k); mt == key {
+				m, _ := v.(map[string]any)
+				return mediaType, m["schema"], true
+			}
+		}
+	}
+
+	return "", nil, false
+}
+
+// validateResponse buffers the response written by next handlers, and writes it only when it matches the spec
+func (doc *openAPISpec) validateResponse(op *specOperation, c *ivy.Context) error {
+	orig := c.ResponseWriter()
+	rw := &bufferedResponseWriter{header: orig.Header()}
+	c.SetResponseWriter(rw)
+
+	err := c.Next()
+	c.SetResponseWriter(orig)
+
+	if err == nil {
+		err = doc.checkResponse(op, rw)
+	}
+
+	if err != nil {
+		// INFO: headers of the discarded response, must not describe the error response
+		orig.Header().Del("Content-Type")
+		orig.Header().Del("Content-Length")
+		return err
+	}
+
+	orig.WriteHeader(rw.statusCode())
+	_, err = orig.Write(rw.body.Bytes())
+	return err
+}
+
+func (doc *openAPISpec) checkResponse(op *specOperation, rw *bufferedResponseWriter) error {
+	code := rw.statusCode()
+	status := strconv.Itoa(code)
+
+	var response any
+	for _, key := range []string{status, status[:1] + "XX", "default"} {
+		if v, ok := op.responses[key]; ok {
+			response = v
+			break
+		}
+	}
+
+	if response == nil {
+		return ivy.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("response status %d is not documented for %s %s", code, op.method, op.path))
+	}
+
+	resp, err := doc.schemas.resolve(response)
+	if err != nil {
+		return err
+	}
+
+	content, _ := resp["content"].(map[string]any)
+	if len(content) == 0 || rw.body.Len() == 0 {
+		return nil
+	}
+
+	contentType := rw.header.Get("Content-Type")
+	mediaType, schema, ok := lookupContent(content, contentType)
+	if !ok {
+		return ivy.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("response content type %q is not documented for %s %s", contentType, op.method, op.path))
+	}
+
+	errs, err := doc.validateBody(schema, mediaType, rw.body.Bytes(), "response", directionResponse)
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return ivy.NewHTTPErrors(http.StatusInternalServerError, "response does not match OpenAPI spec", errs...)
+	}
+
+	return nil
+}
+
+type bufferedResponseWriter struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+// Header implements http.ResponseWriter.
+func (rw *bufferedResponseWriter) Header() http.Header {
+	return rw.header
+}
+
+// Write implements http.ResponseWriter.
+func (rw *bufferedResponseWriter) Write(b []byte) (int, error) {
+	return rw.body.Write(b)
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (rw *bufferedResponseWriter) WriteHeader(statusCode int) {
+	if rw.code == 0 {
+		rw.code = statusCode
+	}
+}
+
+func (rw *bufferedResponseWriter) statusCode() int {
+	if rw.code == 0 {
+		return http.StatusOK
+	}
+	return rw.code
+}
+
+var _ http.ResponseWriter = (*bufferedResponseWriter)(nil)
@@ -0,0 +1,484 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+// direction tells if a schema is validating a request or a response, for `readOnly` and `writeOnly` properties
+type direction int
+
+const (
+	directionRequest direction = iota
+	directionResponse
+)
+
+type schemaError struct {
+	// path is like `.items[0].name`
+	path    string
+	message string
+}
+
+// schemaValidator validates decoded JSON values against JSON schemas of an OpenAPI spec,
+// it supports keywords of both OpenAPI 3.0 schemas (like `nullable`) and 3.1 (JSON Schema 2020-12)
+type schemaValidator struct {
+	root map[string]any
+
+	patterns sync.Map // map[string]*regexp.Regexp
+}
+
+// maxRefDepth guards against reference cycles like `A: {$ref: A}`
+const maxRefDepth = 32
+
+// resolve follows local `$ref`s, until it reaches an object without one
+func (sv *schemaValidator) resolve(v any) (map[string]any, error) {
+	m, _ := v.(map[string]any)
+	for i := 0; m != nil; i++ {
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return m, nil
+		}
+		if i == maxRefDepth {
+			return nil, fmt.Errorf("openapi: too many nested references at %q", ref)
+		}
+
+		target, err := sv.lookupRef(ref)
+		if err != nil {
+			return nil, err
+		}
+		m, _ = target.(map[string]any)
+	}
+	return m, nil
+}
+
+// lookupRef finds a local reference like `#/components/schemas/Pet` as a JSON pointer in the spec
+func (sv *schemaValidator) lookupRef(ref string) (any, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("openapi: only local references are supported, got %q", ref)
+	}
+
+	var node any = sv.root
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if token == "" {
+			continue
+		}
+		if t, err := url.PathUnescape(token); err == nil {
+			token = t
+		}
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+
+		switch n := node.(type) {
+		case map[string]any:
+			node, ok = n[token]
+		case []any:
+			i, err := strconv.Atoi(token)
+			ok = err == nil && i >= 0 && i < len(n)
+			if ok {
+				node = n[i]
+			}
+		default:
+			ok = false
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("openapi: reference %q not found", ref)
+		}
+	}
+
+	return node, nil
+}
+
+func schemaTypes(schema map[string]any) []string {
+	switch t := schema["type"].(type) {
+	case string:
+		types := []string{t}
+		if nullable, _ := schema["nullable"].(bool); nullable {
+			types = append(types, "null")
+		}
+		return types
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// parseParam converts raw param values into a JSON value, as per the schema type.
+// Arrays are read from repeated values when exploded, otherwise from comma separated values
+func (sv *schemaValidator) parseParam(schema map[string]any, values []string, explode bool) (any, error) {
+	types := schemaTypes(schema)
+
+	if slices.Contains(types, "array") {
+		if !explode {
+			values = strings.Split(values[0], ",")
+		}
+
+		items, err := sv.resolve(schema["items"])
+		if err != nil {
+			return nil, err
+		}
+
+		arr := make([]any, 0, len(values))
+		for _, v := range values {
+			item, err := parseScalar(schemaTypes(items), v)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, item)
+		}
+		return arr, nil
+	}
+
+	return parseScalar(types, values[0])
+}
+
+func parseScalar(types []string, s string) (any, error) {
+	if len(types) == 0 {
+		return s, nil
+	}
+
+	for _, t := range types {
+		switch t {
+		case "integer", "number":
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return f, nil
+			}
+		case "boolean":
+			if s == "true" || s == "false" {
+				return s == "true", nil
+			}
+		case "null":
+			if s == "" {
+				return nil, nil
+			}
+		default:
+			return s, nil
+		}
+	}
+
+	return nil, fmt.Errorf("must be of type %s", strings.Join(types, " or "))
+}
+
+func jsonType(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func (sv *schemaValidator) validate(schemaNode any, v any, path string, dir direction, errs *[]schemaError) {
+	// INFO: boolean schemas of JSON Schema, `true` accepts anything, `false` nothing
+	if b, ok := schemaNode.(bool); ok {
+		if !b {
+			*errs = append(*errs, schemaError{path, "is not allowed"})
+		}
+		return
+	}
+
+	schema, err := sv.resolve(schemaNode)
+	if err != nil {
+		*errs = append(*errs, schemaError{path, err.Error()})
+		return
+	}
+	if schema == nil {
+		return
+	}
+
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, schemaError{path, fmt.Sprintf(format, args...)})
+	}
+
+	if types := schemaTypes(schema); len(types) > 0 {
+		actual := jsonType(v)
+		if !slices.Contains(types, actual) && !(actual == "integer" && slices.Contains(types, "number")) {
+			fail("must be of type %s", strings.Join(types, " or "))
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !slices.ContainsFunc(enum, func(e any) bool { return reflect.DeepEqual(e, v) }) {
+		fail("must be one of %s", formatJSON(enum))
+	}
+
+	if c, ok := schema["const"]; ok && !reflect.DeepEqual(c, v) {
+		fail("must be %s", formatJSON(c))
+	}
+
+	switch v := v.(type) {
+	case float64:
+		sv.validateNumber(schema, v, fail)
+	case string:
+		sv.validateString(schema, v, fail)
+	case []any:
+		if n, ok := number(schema["minItems"]); ok && float64(len(v)) < n {
+			fail("must have at least %v items", n)
+		}
+		if n, ok := number(schema["maxItems"]); ok && float64(len(v)) > n {
+			fail("must have at most %v items", n)
+		}
+		if unique, _ := schema["uniqueItems"].(bool); unique {
+			for i := range v {
+				if slices.ContainsFunc(v[:i], func(e any) bool { return reflect.DeepEqual(e, v[i]) }) {
+					fail("must have unique items")
+					break
+				}
+			}
+		}
+
+		prefix, _ := schema["prefixItems"].([]any)
+		for i, item := range v {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i < len(prefix):
+				sv.validate(prefix[i], item, itemPath, dir, errs)
+			case schema["items"] != nil:
+				sv.validate(schema["items"], item, itemPath, dir, errs)
+			}
+		}
+	case map[string]any:
+		sv.validateObject(schema, v, path, dir, errs, fail)
+	}
+
+	if allOf, ok := schema["allOf"].([]any); ok {
+		for _, s := range allOf {
+			sv.validate(s, v, path, dir, errs)
+		}
+	}
+
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		if sv.countMatches(anyOf, v, dir) == 0 {
+			fail("must match at least one of the schemas in anyOf")
+		}
+	}
+
+	if oneOf, ok := schema["oneOf"].([]any); ok {
+		if n := sv.countMatches(oneOf, v, dir); n != 1 {
+			fail("must match exactly one of the schemas in oneOf, matches %d", n)
+		}
+	}
+
+	if not, ok := schema["not"]; ok {
+		var notErrs []schemaError
+		sv.validate(not, v, path, dir, &notErrs)
+		if len(notErrs) == 0 {
+			fail("must not match the schema in not")
+		}
+	}
+}
+
+func (sv *schemaValidator) countMatches(schemas []any, v any, dir direction) int {
+	n := 0
+	for _, s := range schemas {
+		var errs []schemaError
+		sv.validate(s, v, "", dir, &errs)
+		if len(errs) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func (sv *schemaValidator) validateObject(schema map[string]any, v map[string]any, path string, dir direction, errs *[]schemaError, fail func(string, ...any)) {
+	properties, _ := schema["properties"].(map[string]any)
+
+	if required, ok := schema["required"].([]any); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, ok := v[name]; ok {
+				continue
+			}
+
+			// INFO: readOnly properties are only sent in responses, and writeOnly properties only in requests
+			prop, _ := sv.resolve(properties[name])
+			if readOnly, _ := prop["readOnly"].(bool); readOnly && dir == directionRequest {
+				continue
+			}
+			if writeOnly, _ := prop["writeOnly"].(bool); writeOnly && dir == directionResponse {
+				continue
+			}
+
+			*errs = append(*errs, schemaError{path + "." + name, "is required"})
+		}
+	}
+
+	if n, ok := number(schema["minProperties"]); ok && float64(len(v)) < n {
+		fail("must have at least %v properties", n)
+	}
+	if n, ok := number(schema["maxProperties"]); ok && float64(len(v)) > n {
+		fail("must have at most %v properties", n)
+	}
+
+	patternProps, _ := schema["patternProperties"].(map[string]any)
+
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		propPath := path + "." + k
+
+		if prop, ok := properties[k]; ok {
+			sv.validate(prop, v[k], propPath, dir, errs)
+			continue
+		}
+
+		matched := false
+		for pattern, prop := range patternProps {
+			if re, err := sv.compile(pattern); err == nil && re.MatchString(k) {
+				matched = true
+				sv.validate(prop, v[k], propPath, dir, errs)
+			}
+		}
+		if matched {
+			continue
+		}
+
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*errs = append(*errs, schemaError{propPath, "is not allowed"})
+			}
+		case map[string]any:
+			sv.validate(additional, v[k], propPath, dir, errs)
+		}
+	}
+}
+
+func (sv *schemaValidator) validateNumber(schema map[string]any, v float64, fail func(string, ...any)) {
+	if n, ok := number(schema["minimum"]); ok {
+		// INFO: in OpenAPI 3.0, exclusiveMinimum is a boolean modifier of minimum
+		if exclusive, _ := schema["exclusiveMinimum"].(bool); exclusive && v <= n {
+			fail("must be > %v", n)
+		} else if v < n {
+			fail("must be >= %v", n)
+		}
+	}
+	if n, ok := number(schema["exclusiveMinimum"]); ok && v <= n {
+		fail("must be > %v", n)
+	}
+
+	if n, ok := number(schema["maximum"]); ok {
+		if exclusive, _ := schema["exclusiveMaximum"].(bool); exclusive && v >= n {
+			fail("must be < %v", n)
+		} else if v > n {
+			fail("must be <= %v", n)
+		}
+	}
+	if n, ok := number(schema["exclusiveMaximum"]); ok && v >= n {
+		fail("must be < %v", n)
+	}
+
+	if n, ok := number(schema["multipleOf"]); ok && n > 0 {
+		if q := v / n; math.Abs(q-math.Round(q)) > 1e-9 {
+			fail("must be a multiple of %v", n)
+		}
+	}
+}
+
+func (sv *schemaValidator) validateString(schema map[string]any, v string, fail func(string, ...any)) {
+	length := float64(len([]rune(v)))
+	if n, ok := number(schema["minLength"]); ok && length < n {
+		fail("must have length >= %v", n)
+	}
+	if n, ok := number(schema["maxLength"]); ok && length > n {
+		fail("must have length <= %v", n)
+	}
+
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := sv.compile(pattern)
+		if err != nil {
+			fail("invalid pattern %q in spec: %v", pattern, err)
+		} else if !re.MatchString(v) {
+			fail("must match pattern %q", pattern)
+		}
+	}
+
+	if format, ok := schema["format"].(string); ok && !validFormat(format, v) {
+		fail("must be a valid %s", format)
+	}
+}
+
+func (sv *schemaValidator) compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := sv.patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sv.patterns.Store(pattern, re)
+	return re, nil
+}
+
+// validFormat checks commonly used string formats, unknown formats are only annotations, and always valid
+func validFormat(format string, v string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, v)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, v)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(v)
+		return err == nil && addr.Address == v
+	case "uuid":
+		_, err := ivy.ParseUUID(v)
+		return err == nil
+	case "uri":
+		u, err := url.Parse(v)
+		return err == nil && u.Scheme != ""
+	case "ipv4":
+		addr, err := netip.ParseAddr(v)
+		return err == nil && addr.Is4()
+	case "ipv6":
+		addr, err := netip.ParseAddr(v)
+		return err == nil && addr.Is6()
+	}
+	return true
+}
+
+func number(v any) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
+
+func formatJSON(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
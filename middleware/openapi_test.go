@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+const petsSpec = `
+openapi: 3.0.3
+info:
+  title: Pets
+  version: "1.0"
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /pets:
+    get:
+      parameters:
+        - $ref: "#/components/parameters/Limit"
+        - name: tag
+          in: query
+          schema:
+            type: array
+            items: {type: string, enum: [cat, dog]}
+      responses:
+        "200":
+          description: pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: "#/components/schemas/Pet"}
+    post:
+      parameters:
+        - name: X-Tenant
+          in: header
+          required: true
+          schema: {type: string, format: uuid}
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/Pet"}
+      responses:
+        "201":
+          description: created
+  /pets/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema: {type: integer, minimum: 1}
+    get:
+      responses:
+        default:
+          description: pet
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Pet"}
+components:
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      schema: {type: integer, maximum: 100}
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      additionalProperties: false
+      properties:
+        id: {type: integer, readOnly: true}
+        name: {type: string, minLength: 1}
+        owner:
+          type: string
+          nullable: true
+`
+
+func TestOpenAPIValidator(t *testing.T) {
+	validator, err := OpenAPIValidatorFS(fstest.MapFS{"openapi.yaml": {Data: []byte(petsSpec)}}, "openapi.yaml", OpenAPIOptions{ValidateResponses: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := ivy.NewRouter()
+	r.Use(validator)
+	r.Get("/v1/pets", func(c *ivy.Context) error {
+		return c.SendJSON([]map[string]any{{"id": 1, "name": "tom", "owner": nil}})
+	})
+	r.Post("/v1/pets", func(c *ivy.Context) error {
+		var pet map[string]any
+		if err := c.ParseBodyInto(&pet); err != nil {
+			return err
+		}
+		return c.SendStatus(http.StatusCreated)
+	})
+	r.Get("/v1/pets/{id}", func(c *ivy.Context) error {
+		if c.PathParam("id") == "2" {
+			return c.SendJSON(map[string]any{"id": 2})
+		}
+		return c.SendJSON(map[string]any{"id": 1, "name": "tom"})
+	})
+	r.Get("/v1/unknown", func(c *ivy.Context) error {
+		return c.SendString("ok")
+	})
+
+	tests := []struct {
+		name       string
+		method     string
+		route      string
+		headers    map[string]string
+		body       string
+		wantStatus int
+		wantBody   []string
+	}{
+		{
+			name:       "1. valid request and response",
+			method:     http.MethodGet,
+			route:      "/v1/pets?limit=10&tag=cat&tag=dog",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "2. invalid query params",
+			method:     http.MethodGet,
+			route:      "/v1/pets?limit=101&tag=cow",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   []string{`query "limit": must be <= 100`, `query "tag[0]": must be one of ["cat","dog"]`},
+		},
+		{
+			name:       "3. invalid path param",
+			method:     http.MethodGet,
+			route:      "/v1/pets/abc",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   []string{`path "id": must be of type integer`},
+		},
+		{
+			name:       "4. valid body, readOnly property is not required in requests",
+			method:     http.MethodPost,
+			route:      "/v1/pets",
+			headers:    map[string]string{"X-Tenant": "7f1c8c1e-9b7e-4a5e-8f4a-2d6f0c7c9a10", "Content-Type": "application/json"},
+			body:       `{"name":"tom"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "5. invalid body and header",
+			method:     http.MethodPost,
+			route:      "/v1/pets",
+			headers:    map[string]string{"X-Tenant": "abc", "Content-Type": "application/json"},
+			body:       `{"name":"","color":"red"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   []string{`header "X-Tenant": must be a valid uuid`, `body "color": is not allowed`, `body "name": must have length >= 1`},
+		},
+		{
+			name:       "6. missing body",
+			method:     http.MethodPost,
+			route:      "/v1/pets",
+			headers:    map[string]string{"X-Tenant": "7f1c8c1e-9b7e-4a5e-8f4a-2d6f0c7c9a10"},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   []string{`body "$": is required`},
+		},
+		{
+			name:       "7. unsupported content type",
+			method:     http.MethodPost,
+			route:      "/v1/pets",
+			headers:    map[string]string{"X-Tenant": "7f1c8c1e-9b7e-4a5e-8f4a-2d6f0c7c9a10", "Content-Type": "text/plain"},
+			body:       "tom",
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:       "8. response violating the spec",
+			method:     http.MethodGet,
+			route:      "/v1/pets/2",
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   []string{`response does not match OpenAPI spec: response "name": is required`},
+		},
+		{
+			name:       "9. routes not in spec are passed through",
+			method:     http.MethodGet,
+			route:      "/v1/unknown",
+			wantStatus: http.StatusOK,
+			wantBody:   []string{"ok"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.route, strings.NewReader(tt.body))
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status code: got %d, want %d (body: %q)", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			for _, want := range tt.wantBody {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Errorf("body: %q not found in %q", want, w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestOpenAPIValidator_RejectUnknownRoutes(t *testing.T) {
+	validator, err := OpenAPIValidator([]byte(petsSpec), OpenAPIOptions{RejectUnknownRoutes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := ivy.NewRouter()
+	r.Use(validator)
+	r.Get("/v1/unknown", func(c *ivy.Context) error { return c.SendString("ok") })
+	r.Delete("/v1/pets", func(c *ivy.Context) error { return nil })
+
+	for route, want := range map[string]int{"GET /v1/unknown": http.StatusNotFound, "DELETE /v1/pets": http.StatusMethodNotAllowed} {
+		method, path, _ := strings.Cut(route, " ")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		if w.Code != want {
+			t.Errorf("%s: got %d, want %d", route, w.Code, want)
+		}
+	}
+}
+
+func TestOpenAPIValidator_InvalidSpec(t *testing.T) {
+	_, err := OpenAPIValidator([]byte(`{"swagger": "2.0"}`))
+	if err == nil || !strings.Contains(err.Error(), "unsupported spec version") {
+		t.Errorf("expected unsupported version error, got %v", err)
+	}
+
+	_, err = OpenAPIValidator([]byte("openapi: 3.1.0\npaths:\n  /a:\n    $ref: 'other.yaml#/paths/a'\n"))
+	if err == nil || !strings.Contains(err.Error(), "local references") {
+		t.Errorf("expected local reference error, got %v", err)
+	}
+
+	_, err = OpenAPIValidator([]byte("openapi: 3.1.0\ncomponents:\n  schemas:\n    Pet: &pet\n      type: object\n"))
+	if err == nil || !strings.Contains(err.Error(), `anchor "&pet" is not supported`) {
+		t.Errorf("expected unsupported anchor error, got %v", err)
+	}
+}
@@ -0,0 +1,314 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"hash/maphash"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+// RateLimitAlgorithm decides, how requests are counted against a limit
+type RateLimitAlgorithm int
+
+const (
+	// TokenBucket allows bursts of up to Requests, refilling at Requests per Window
+	TokenBucket RateLimitAlgorithm = iota
+
+	// SlidingWindow allows Requests per Window, weighing the previous window's count by how much of it still overlaps
+	SlidingWindow
+)
+
+// Limit is the number of requests allowed per window
+type Limit struct {
+	Algorithm RateLimitAlgorithm
+	Requests  int
+	Window    time.Duration
+}
+
+// RateLimitDecision is the result of counting a request against a limit
+type RateLimitDecision struct {
+	Allowed bool
+
+	// Remaining requests, that are allowed right now
+	Remaining int
+
+	// Reset is the time, until the limit is fully available again
+	Reset time.Duration
+
+	// RetryAfter is the time, until the next request is allowed, when it is not allowed now
+	RetryAfter time.Duration
+}
+
+// RateLimitStore keeps rate limiting state. Distributed stores (like redis) must count the request, and decide atomically
+type RateLimitStore interface {
+	Allow(ctx context.Context, key string, limit Limit) (RateLimitDecision, error)
+}
+
+// RateLimitOptions configures [RateLimit]
+type RateLimitOptions struct {
+	Limit
+
+	// KeyFunc identifies clients, that are limited separately, defaults to [KeyByIP].
+	// Requests, for which it returns an empty key, are limited by client IP
+	KeyFunc func(c *ivy.Context) string
+
+	// Store defaults to an in-memory store, that is not shared with other RateLimit middlewares
+	Store RateLimitStore
+
+	// Prefix is prepended to keys, so that multiple limits can share a Store
+	Prefix string
+}
+
+func (o *RateLimitOptions) withDefaultsIfMissing() {
+	if o.KeyFunc == nil {
+		o.KeyFunc = KeyByIP()
+	}
+	if o.Store == nil {
+		o.Store = NewMemoryStore()
+	}
+}
+
+// RateLimit limits number of requests per client, and responds with an [ivy.HTTPError] of 429 Too Many Requests, once the limit is exceeded.
+//
+// Responses carry `RateLimit-Limit`, `RateLimit-Remaining`, `RateLimit-Reset` and `RateLimit-Policy` headers,
+// and `Retry-After` when limited. When the store fails, requests are let through, and the error is logged.
+//
+// Example:
+//
+//	r.Post("/login", middleware.RateLimit(middleware.RateLimitOptions{
+//	    Limit: middleware.Limit{Algorithm: middleware.SlidingWindow, Requests: 5, Window: time.Minute},
+//	}), loginHandler)
+func RateLimit(opts RateLimitOptions) ivy.Handler {
+	opts.withDefaultsIfMissing()
+
+	if opts.Requests <= 0 || opts.Window <= 0 {
+		panic("ivy/middleware: RateLimit needs positive Requests and Window")
+	}
+
+	policy := fmt.Sprintf("%d;w=%d", opts.Requests, int(math.Ceil(opts.Window.Seconds())))
+
+	ipKey := KeyByIP()
+
+	return func(c *ivy.Context) error {
+		key := opts.KeyFunc(c)
+		if key == "" {
+			key = ipKey(c)
+		}
+
+		decision, err := opts.Store.Allow(c, opts.Prefix+key, opts.Limit)
+		if err != nil {
+			c.Logger.Warn("rate limit store failed, letting the request through", "err", err)
+			return c.Next()
+		}
+
+		h := c.ResponseWriter().Header()
+		h.Set("RateLimit-Limit", strconv.Itoa(opts.Requests))
+		h.Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+		h.Set("RateLimit-Policy", policy)
+
+		if !decision.Allowed {
+			h.Set("Retry-After", strconv.Itoa(max(ceilSeconds(decision.RetryAfter), 1)))
+			return ivy.NewHTTPError(http.StatusTooManyRequests, http.StatusText(http.StatusTooManyRequests))
+		}
+
+		return c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// KeyByIP keys requests by client IP, as seen in http.Request.RemoteAddr.
+// Behind proxies, RemoteAddr must be set to the client IP first, or requests keyed by a header set by the proxy (see [KeyByHeader])
+func KeyByIP() func(c *ivy.Context) string {
+	return func(c *ivy.Context) string {
+		addr := c.Request().RemoteAddr
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			return "ip:" + host
+		}
+		return "ip:" + addr
+	}
+}
+
+// KeyByHeader keys requests by value of header, like an API key
+func KeyByHeader(header string) func(c *ivy.Context) string {
+	return func(c *ivy.Context) string {
+		if v := c.Request().Header.Get(header); v != "" {
+			return "header:" + v
+		}
+		return ""
+	}
+}
+
+// KeyByKV keys requests by value of key in c.KV, like the ID of an authenticated user set by an auth middleware
+func KeyByKV(key any) func(c *ivy.Context) string {
+	return func(c *ivy.Context) string {
+		if v, ok := c.KV.Lookup(key); ok && v != nil {
+			return fmt.Sprintf("kv:%v", v)
+		}
+		return ""
+	}
+}
+
+const memoryStoreShards = 32
+
+// sweepInterval is how often a shard of MemoryStore drops expired entries
+const sweepInterval = time.Minute
+
+// MemoryStore is an in-memory [RateLimitStore], sharded to reduce lock contention.
+// Entries expire once their limit is fully available again, and are dropped lazily
+type MemoryStore struct {
+	seed   maphash.Seed
+	shards [memoryStoreShards]memoryShard
+
+	// now is replaced in tests
+	now func() time.Time
+}
+
+type memoryShard struct {
+	mu        sync.Mutex
+	entries   map[string]*rateLimitEntry
+	lastSweep time.Time
+}
+
+type rateLimitEntry struct {
+	expires time.Time
+
+	// token bucket
+	tokens float64
+	last   time.Time
+
+	// sliding window
+	windowStart time.Time
+	prev, cur   int
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{seed: maphash.MakeSeed(), now: time.Now}
+	for i := range s.shards {
+		s.shards[i].entries = map[string]*rateLimitEntry{}
+	}
+	return s
+}
+
+// Allow implements RateLimitStore.
+func (s *MemoryStore) Allow(ctx context.Context, key string, limit Limit) (RateLimitDecision, error) {
+	now := s.now()
+
+	shard := &s.shards[maphash.String(s.seed, key)%memoryStoreShards]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if now.Sub(shard.lastSweep) > sweepInterval {
+		for k, e := range shard.entries {
+			if now.After(e.expires) {
+				delete(shard.entries, k)
+			}
+		}
+		shard.lastSweep = now
+	}
+
+	e, ok := shard.entries[key]
+	if !ok || now.After(e.expires) {
+		e = &rateLimitEntry{tokens: float64(limit.Requests), last: now, windowStart: now.Truncate(limit.Window)}
+		shard.entries[key] = e
+	}
+
+	var d RateLimitDecision
+	switch limit.Algorithm {
+	case SlidingWindow:
+		d = e.slidingWindow(now, limit)
+	default:
+		d = e.tokenBucket(now, limit)
+	}
+
+	e.expires = now.Add(d.Reset)
+	return d, nil
+}
+
+func (e *rateLimitEntry) tokenBucket(now time.Time, limit Limit) RateLimitDecision {
+	capacity := float64(limit.Requests)
+	perSecond := capacity / limit.Window.Seconds()
+
+	e.tokens = math.Min(capacity, e.tokens+now.Sub(e.last).Seconds()*perSecond)
+	e.last = now
+
+	d := RateLimitDecision{}
+	if e.tokens >= 1 {
+		e.tokens--
+		d.Allowed = true
+	} else {
+		d.RetryAfter = seconds((1 - e.tokens) / perSecond)
+	}
+
+	d.Remaining = int(e.tokens)
+	d.Reset = seconds((capacity - e.tokens) / perSecond)
+	return d
+}
+
+func (e *rateLimitEntry) slidingWindow(now time.Time, limit Limit) RateLimitDecision {
+	window := limit.Window
+	start := now.Truncate(window)
+
+	if !start.Equal(e.windowStart) {
+		if start.Sub(e.windowStart) == window {
+			e.prev = e.cur
+		} else {
+			e.prev = 0
+		}
+		e.cur = 0
+		e.windowStart = start
+	}
+
+	elapsed := now.Sub(start)
+	// INFO: previous window's count is weighed by the part of it, that still falls within the sliding window
+	weight := 1 - elapsed.Seconds()/window.Seconds()
+	estimated := float64(e.prev)*weight + float64(e.cur)
+
+	d := RateLimitDecision{}
+	if estimated+1 <= float64(limit.Requests) {
+		e.cur++
+		estimated++
+		d.Allowed = true
+	} else {
+		d.RetryAfter = e.retryAfter(elapsed, limit)
+	}
+
+	d.Remaining = max(int(float64(limit.Requests)-estimated), 0)
+	// current window's requests stop counting, once the next window is over
+	d.Reset = window - elapsed + window
+	if e.cur == 0 {
+		d.Reset = window - elapsed
+	}
+	return d
+}
+
+// retryAfter finds, when estimated count of the sliding window drops enough for one more request
+func (e *rateLimitEntry) retryAfter(elapsed time.Duration, limit Limit) time.Duration {
+	window := limit.Window.Seconds()
+	allowed := float64(limit.Requests - 1)
+
+	if float64(e.cur) <= allowed && e.prev > 0 {
+		// prev * (1 - t/window) + cur <= allowed, within the current window
+		t := window * (1 - (allowed-float64(e.cur))/float64(e.prev))
+		return seconds(t) - elapsed
+	}
+
+	// in the next window, current window becomes the previous one
+	t := window * (1 - allowed/float64(e.cur))
+	return limit.Window - elapsed + seconds(t)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
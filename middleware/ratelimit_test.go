@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func TestRateLimit(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	r := ivy.NewRouter()
+	r.Use(func(c *ivy.Context) error {
+		if user := c.Request().Header.Get("X-User"); user != "" {
+			c.KV.Set("user", user)
+		}
+		return c.Next()
+	})
+	r.Get("/bucket", RateLimit(RateLimitOptions{
+		Limit: Limit{Algorithm: TokenBucket, Requests: 2, Window: 10 * time.Second},
+		Store: store,
+	}), func(c *ivy.Context) error { return c.SendString("ok") })
+	r.Get("/window", RateLimit(RateLimitOptions{
+		Limit:   Limit{Algorithm: SlidingWindow, Requests: 2, Window: 10 * time.Second},
+		KeyFunc: KeyByKV("user"),
+		Store:   store,
+		Prefix:  "window:",
+	}), func(c *ivy.Context) error { return c.SendString("ok") })
+
+	do := func(path string, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if user != "" {
+			req.Header.Set("X-User", user)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("token bucket", func(t *testing.T) {
+		if w := do("/bucket", ""); w.Code != http.StatusOK || w.Header().Get("RateLimit-Remaining") != "1" || w.Header().Get("RateLimit-Policy") != "2;w=10" {
+			t.Errorf("1st request: got %d %v", w.Code, w.Header())
+		}
+		do("/bucket", "")
+
+		w := do("/bucket", "")
+		if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "5" || w.Header().Get("RateLimit-Remaining") != "0" {
+			t.Errorf("3rd request: got %d %v", w.Code, w.Header())
+		}
+
+		// a token is refilled every 5 seconds
+		now = now.Add(5 * time.Second)
+		if w := do("/bucket", ""); w.Code != http.StatusOK {
+			t.Errorf("after refill: got %d", w.Code)
+		}
+	})
+
+	t.Run("sliding window", func(t *testing.T) {
+		now = time.Date(2024, 1, 1, 0, 1, 0, 0, time.UTC)
+
+		do("/window", "alice")
+		do("/window", "alice")
+		if w := do("/window", "alice"); w.Code != http.StatusTooManyRequests {
+			t.Errorf("3rd request: got %d", w.Code)
+		}
+		// other users have their own limit
+		if w := do("/window", "bob"); w.Code != http.StatusOK {
+			t.Errorf("other user: got %d", w.Code)
+		}
+
+		// 2 seconds into the next window, 80% of previous window's requests still count
+		now = now.Add(12 * time.Second)
+		if w := do("/window", "alice"); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "3" {
+			t.Errorf("next window: got %d, retry after %q", w.Code, w.Header().Get("Retry-After"))
+		}
+
+		now = now.Add(3 * time.Second)
+		if w := do("/window", "alice"); w.Code != http.StatusOK {
+			t.Errorf("once half of previous window has slid out: got %d", w.Code)
+		}
+	})
+}
+
+type failingStore struct{}
+
+func (failingStore) Allow(ctx context.Context, key string, limit Limit) (RateLimitDecision, error) {
+	return RateLimitDecision{}, errors.New("connection refused")
+}
+
+func TestRateLimit_StoreFailure(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Use(RateLimit(RateLimitOptions{Limit: Limit{Requests: 1, Window: time.Second}, Store: failingStore{}}))
+	r.Get("/", func(c *ivy.Context) error { return c.SendString("ok") })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("requests must be let through, when store fails, got %d", w.Code)
+	}
+}
+
+func TestMemoryStore_Eviction(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	limit := Limit{Requests: 1, Window: time.Second}
+	for i := range 200 {
+		store.Allow(context.Background(), fmt.Sprint("old-", i), limit)
+	}
+
+	// INFO: expired entries are dropped from a shard, when it is next used
+	now = now.Add(2 * sweepInterval)
+	for i := range 200 {
+		store.Allow(context.Background(), fmt.Sprint("new-", i), limit)
+	}
+
+	entries := 0
+	for i := range store.shards {
+		entries += len(store.shards[i].entries)
+	}
+	if entries != 200 {
+		t.Errorf("expected expired entries to be dropped, got %d entries", entries)
+	}
+}
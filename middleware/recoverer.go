@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+// PanicError is the error, that [Recoverer] passes to router's ErrorHandler for a recovered panic
+type PanicError struct {
+	// Value is the value, that panic was called with
+	Value any
+
+	// Stack is the stack trace of the panicking goroutine
+	Stack []byte
+}
+
+// Error implements error.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns panic value, if it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Recoverer recovers panics from the next handlers, logs them with their stack trace via c.Logger,
+// and passes them as [PanicError] to router's ErrorHandler.
+//
+// Panics with [http.ErrAbortHandler] are re-panicked, so that net/http aborts the response as intended.
+// When the response has already been (partially) written, panic is only logged, as its status can no longer be changed.
+//
+// Example:
+//
+//	r.Use(middleware.Recoverer())
+func Recoverer() ivy.Handler {
+	return func(c *ivy.Context) (err error) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+
+			if e, ok := rec.(error); ok && errors.Is(e, http.ErrAbortHandler) {
+				panic(rec)
+			}
+
+			pe := &PanicError{Value: rec, Stack: debug.Stack()}
+			// INFO: handlers run by ivy.Context.NextWithTimeout panic in a goroutine of their own, whose stack is more useful
+			if gp, ok := rec.(*ivy.GoroutinePanic); ok {
+				pe = &PanicError{Value: gp.Value, Stack: gp.Stack}
+			}
+			c.Logger.Error("panic recovered", "request_id", c.GetRequestID(), "panic", pe.Value, "stack", string(pe.Stack))
+
+			if c.Committed() {
+				// INFO: status, and probably some of the body has been sent, an error response now would only corrupt it
+				err = nil
+				return
+			}
+
+			err = pe
+		}()
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func TestRecoverer(t *testing.T) {
+	var handled error
+
+	r := ivy.NewRouter()
+	r.ErrorHandler = func(c *ivy.Context, err error) {
+		handled = err
+		c.Status(http.StatusInternalServerError).SendString("recovered")
+	}
+	r.Use(Recoverer())
+	r.Get("/nil", func(c *ivy.Context) error {
+		var m map[string]int
+		m["x"] = 1
+		return nil
+	})
+	r.Get("/partial", func(c *ivy.Context) error {
+		c.SendString("partial")
+		panic("after write")
+	})
+
+	t.Run("panic is passed to ErrorHandler", func(t *testing.T) {
+		handled = nil
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/nil", nil))
+
+		if w.Code != http.StatusInternalServerError || w.Body.String() != "recovered" {
+			t.Errorf("response: got %d %q", w.Code, w.Body.String())
+		}
+
+		var pe *PanicError
+		if !errors.As(handled, &pe) {
+			t.Fatalf("expected PanicError, got %v", handled)
+		}
+		if !strings.Contains(pe.Error(), "assignment to entry in nil map") || len(pe.Stack) == 0 {
+			t.Errorf("panic error: got %q, with stack of %d bytes", pe.Error(), len(pe.Stack))
+		}
+	})
+
+	t.Run("committed response is left as is", func(t *testing.T) {
+		handled = nil
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/partial", nil))
+
+		if w.Code != http.StatusOK || w.Body.String() != "partial" || handled != nil {
+			t.Errorf("response: got %d %q, error handler called with %v", w.Code, w.Body.String(), handled)
+		}
+	})
+}
+
+func TestRecoverer_ErrAbortHandler(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Use(Recoverer())
+	r.Get("/abort", func(c *ivy.Context) error {
+		panic(http.ErrAbortHandler)
+	})
+
+	defer func() {
+		if rec := recover(); rec != http.ErrAbortHandler {
+			t.Errorf("expected http.ErrAbortHandler to be re-panicked, got %v", rec)
+		}
+	}()
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+// Timeout cancels request context of the next handlers after d. When they have not returned by then,
+// their buffered response is dropped, and [context.DeadlineExceeded] is passed to router's ErrorHandler, which responds instead.
+// Streaming responses get d as the write deadline, once they are flushed.
+//
+// See [ivy.Context.NextWithTimeout] for details, and [ivy.Route.Timeout] to set a timeout on a single route.
+//
+// Example:
+//
+//	r.Use(middleware.Timeout(10 * time.Second))
+func Timeout(d time.Duration) ivy.Handler {
+	return func(c *ivy.Context) error {
+		return c.NextWithTimeout(d)
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func TestTimeout(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Use(Recoverer(), Timeout(20*time.Millisecond))
+	r.Get("/fast", func(c *ivy.Context) error { return c.SendString("ok") })
+	r.Get("/slow", func(c *ivy.Context) error {
+		select {
+		case <-c.Done():
+			return c.Err()
+		case <-time.After(time.Second):
+			return c.SendString("too late")
+		}
+	})
+	r.Get("/panic", func(c *ivy.Context) error { panic("boom") })
+
+	tests := map[string]int{
+		"/fast":  http.StatusOK,
+		"/slow":  http.StatusServiceUnavailable,
+		"/panic": http.StatusInternalServerError,
+	}
+
+	for path, want := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != want {
+			t.Errorf("%s: got %d, want %d", path, w.Code, want)
+		}
+	}
+}
+
+func panickingHandler(c *ivy.Context) error {
+	panic("boom")
+}
+
+func TestTimeout_PanicStack(t *testing.T) {
+	var got *PanicError
+
+	r := ivy.NewRouter()
+	r.ErrorHandler = func(c *ivy.Context, err error) {
+		errors.As(err, &got)
+		ivy.DefaultErrorHandler(c, err)
+	}
+	r.Use(Recoverer(), Timeout(time.Second))
+	r.Get("/panic", panickingHandler)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if got == nil || got.Value != "boom" {
+		t.Fatalf("expected PanicError with the panic value, got %v", got)
+	}
+	// stack is of the goroutine, that the handler panicked in
+	if !strings.Contains(string(got.Stack), "panickingHandler") {
+		t.Errorf("stack does not have the panicking handler:\n%s", got.Stack)
+	}
+}
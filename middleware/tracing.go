@@ -0,0 +1,309 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+// Span is a timed operation of a trace, identified as per [W3C Trace Context](https://www.w3.org/TR/trace-context/)
+type Span struct {
+	TraceID  string
+	SpanID   string
+	ParentID string
+
+	// TraceState is the vendor specific `tracestate`, that is propagated as is
+	TraceState string
+	Sampled    bool
+
+	Name  string
+	Start time.Time
+	End   time.Time
+
+	// Error is set, when the operation failed
+	Error string
+
+	mu         sync.Mutex
+	attributes map[string]any
+	exporter   SpanExporter
+	ended      bool
+}
+
+// SetAttribute sets an attribute on the span, like "db.system"
+func (s *Span) SetAttribute(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.attributes == nil {
+		s.attributes = make(map[string]any, 1)
+	}
+	s.attributes[key] = value
+}
+
+// Attributes returns a copy of span's attributes
+func (s *Span) Attributes() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attrs := make(map[string]any, len(s.attributes))
+	for k, v := range s.attributes {
+		attrs[k] = v
+	}
+	return attrs
+}
+
+// Traceparent returns the `traceparent` header, that makes this span the parent of downstream spans
+func (s *Span) Traceparent() string {
+	flags := "00"
+	if s.Sampled {
+		flags = "01"
+	}
+	return "00-" + s.TraceID + "-" + s.SpanID + "-" + flags
+}
+
+// Finish ends the span, and exports it, if it is sampled. err marks the span as failed
+func (s *Span) Finish(ctx context.Context, err error) {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.End = time.Now()
+	if err != nil && s.Error == "" {
+		s.Error = err.Error()
+	}
+	s.mu.Unlock()
+
+	if s.Sampled && s.exporter != nil {
+		if err := s.exporter.ExportSpan(ctx, s); err != nil {
+			ivy.Logger.Warn("exporting span", "trace_id", s.TraceID, "span_id", s.SpanID, "err", err)
+		}
+	}
+}
+
+// SpanExporter receives finished (and sampled) spans, it is where tracing backends (like OpenTelemetry) plug in
+type SpanExporter interface {
+	ExportSpan(ctx context.Context, span *Span) error
+}
+
+type spanCtxKey struct{}
+
+// SpanFromContext returns the span, that [Tracing] (or [StartSpan]) put on ctx, and nil if there is none
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanCtxKey{}).(*Span)
+	return span
+}
+
+// ContextWithSpan returns a copy of ctx carrying span, see [SpanFromContext]
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanCtxKey{}, span)
+}
+
+// StartSpan starts a child span of the span in ctx (or a new trace, when there is none), and returns ctx carrying it.
+// Span must be finished with [Span.Finish], it is exported with the exporter of its parent.
+//
+// Example:
+//
+//	ctx, span := middleware.StartSpan(c, "load user")
+//	user, err := db.LoadUser(ctx, id)
+//	span.Finish(ctx, err)
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	span := &Span{TraceID: newID(16), SpanID: newID(8), Sampled: true, Name: name, Start: time.Now()}
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.TraceID = parent.TraceID
+		span.ParentID = parent.SpanID
+		span.TraceState = parent.TraceState
+		span.Sampled = parent.Sampled
+		span.exporter = parent.exporter
+	}
+	return ContextWithSpan(ctx, span), span
+}
+
+// TracingOptions configures [Tracing]
+type TracingOptions struct {
+	// Exporter receives spans of requests, they are not exported when it is nil
+	Exporter SpanExporter
+}
+
+// Tracing continues the trace of incoming `traceparent` and `tracestate` headers (or starts a new one), with a span for the request.
+//
+// Span is put on the request context (see [SpanFromContext]), and its trace_id and span_id on c.Logger.
+// Outgoing requests propagate it, when sent with [TracingTransport].
+//
+// Example:
+//
+//	r.Use(middleware.Tracing(middleware.TracingOptions{Exporter: middleware.NewJSONExporter(os.Stdout)}))
+func Tracing(opts ...TracingOptions) ivy.Handler {
+	var opt TracingOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	return func(c *ivy.Context) error {
+		req := c.Request()
+
+		span := &Span{SpanID: newID(8), Start: time.Now(), exporter: opt.Exporter}
+		if traceID, parentID, sampled, ok := parseTraceparent(req.Header.Get("traceparent")); ok {
+			span.TraceID, span.ParentID, span.Sampled = traceID, parentID, sampled
+			span.TraceState = strings.TrimSpace(strings.Join(req.Header.Values("tracestate"), ","))
+		} else {
+			span.TraceID, span.Sampled = newID(16), true
+		}
+
+		span.SetAttribute("http.request.method", req.Method)
+		span.SetAttribute("url.path", req.URL.Path)
+
+		c.Logger = c.Logger.With("trace_id", span.TraceID, "span_id", span.SpanID)
+		c.SetContext(ContextWithSpan(req.Context(), span))
+
+		var err error
+
+		// INFO: span is finished once ErrorHandler has written the response, so that status code is the one sent.
+		// Its name is set by then too, as route of a mounted router is only known after c.Next()
+		c.AfterResponse(func() {
+			span.Name = req.Method
+			if pattern := c.RoutePattern(); pattern != "" {
+				if _, path, ok := strings.Cut(pattern, " "); ok {
+					pattern = strings.TrimLeft(path, " ")
+				}
+				span.Name = req.Method + " " + pattern
+				span.SetAttribute("http.route", pattern)
+			}
+
+			status := sentStatus(c)
+			span.SetAttribute("http.response.status_code", status)
+			if err == nil && status >= http.StatusInternalServerError {
+				span.Error = http.StatusText(status)
+			}
+
+			span.Finish(context.WithoutCancel(c), err)
+		})
+
+		err = c.Next()
+		return err
+	}
+}
+
+// parseTraceparent parses `traceparent` header, like `00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01`
+func parseTraceparent(h string) (traceID, parentID string, sampled bool, ok bool) {
+	parts := strings.Split(strings.TrimSpace(h), "-")
+	if len(parts) < 4 {
+		return "", "", false, false
+	}
+
+	version, traceID, parentID, flags := parts[0], parts[1], parts[2], parts[3]
+	// INFO: future versions may append more fields, while version 00 has exactly four
+	if !isHex(version, 2) || version == "ff" || (version == "00" && len(parts) != 4) {
+		return "", "", false, false
+	}
+	if !isHex(traceID, 32) || traceID == strings.Repeat("0", 32) || !isHex(parentID, 16) || parentID == strings.Repeat("0", 16) || !isHex(flags, 2) {
+		return "", "", false, false
+	}
+
+	b, _ := hex.DecodeString(flags)
+	return traceID, parentID, b[0]&0x01 == 1, true
+}
+
+// isHex reports whether s is n lowercase hex characters
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// newID returns n random bytes, hex encoded
+func newID(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// TracingTransport is a http.RoundTripper, that propagates span of the request context to outgoing requests,
+// with `traceparent` and `tracestate` headers
+//
+// Example:
+//
+//	client := &http.Client{Transport: &middleware.TracingTransport{}}
+//	req, _ := http.NewRequestWithContext(c, http.MethodGet, "http://inventory/items", nil)
+//	resp, err := client.Do(req)
+type TracingTransport struct {
+	// Base sends the requests, defaults to http.DefaultTransport
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *TracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	span := SpanFromContext(req.Context())
+	if span == nil {
+		return base.RoundTrip(req)
+	}
+
+	// INFO: RoundTrippers must not modify the request they are given
+	req = req.Clone(req.Context())
+	req.Header.Set("traceparent", span.Traceparent())
+	if span.TraceState != "" {
+		req.Header.Set("tracestate", span.TraceState)
+	} else {
+		req.Header.Del("tracestate")
+	}
+	return base.RoundTrip(req)
+}
+
+// jsonExporter writes spans as JSON, one per line
+type jsonExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONExporter creates a SpanExporter, that writes spans to w as JSON, one per line. w defaults to os.Stdout
+func NewJSONExporter(w io.Writer) SpanExporter {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &jsonExporter{w: w}
+}
+
+// ExportSpan implements SpanExporter.
+func (e *jsonExporter) ExportSpan(ctx context.Context, span *Span) error {
+	b, err := json.Marshal(map[string]any{
+		"trace_id":    span.TraceID,
+		"span_id":     span.SpanID,
+		"parent_id":   span.ParentID,
+		"trace_state": span.TraceState,
+		"name":        span.Name,
+		"start":       span.Start,
+		"end":         span.End,
+		"duration_ms": float64(span.End.Sub(span.Start).Microseconds()) / 1000,
+		"error":       span.Error,
+		"attributes":  span.Attributes(),
+	})
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.w.Write(append(b, '\n'))
+	return err
+}
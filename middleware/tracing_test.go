@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func TestTracing(t *testing.T) {
+	var downstream http.Header
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downstream = r.Header.Clone()
+	}))
+	defer backend.Close()
+
+	var logs bytes.Buffer
+	defer func(l *slog.Logger) { ivy.Logger = l }(ivy.Logger)
+	ivy.Logger = slog.New(slog.NewJSONHandler(&logs, nil))
+
+	var exported bytes.Buffer
+	client := &http.Client{Transport: &TracingTransport{}}
+
+	r := ivy.NewRouter()
+	r.Use(Tracing(TracingOptions{Exporter: NewJSONExporter(&exported)}))
+	r.Get("/users/{id}", func(c *ivy.Context) error {
+		c.Logger.Info("loading user")
+
+		_, span := StartSpan(c, "load user")
+		span.Finish(c, errors.New("not found"))
+
+		req, _ := http.NewRequestWithContext(c, http.MethodGet, backend.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	})
+
+	t.Run("continues incoming trace", func(t *testing.T) {
+		logs.Reset()
+		exported.Reset()
+
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		req.Header.Set("tracestate", "vendor=abc")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		traceID, parentID, sampled, ok := parseTraceparent(downstream.Get("traceparent"))
+		if !ok || traceID != "4bf92f3577b34da6a3ce929d0e0e4736" || parentID == "00f067aa0ba902b7" || !sampled {
+			t.Errorf("downstream traceparent: got %q", downstream.Get("traceparent"))
+		}
+		if downstream.Get("tracestate") != "vendor=abc" {
+			t.Errorf("downstream tracestate: got %q", downstream.Get("tracestate"))
+		}
+
+		if !strings.Contains(logs.String(), `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`) || !strings.Contains(logs.String(), `"span_id":"`+parentID+`"`) {
+			t.Errorf("logs must carry trace and span ids, got %s", logs.String())
+		}
+
+		lines := strings.Split(strings.TrimSpace(exported.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("expected child and request spans to be exported, got %d lines", len(lines))
+		}
+
+		var child, server map[string]any
+		json.Unmarshal([]byte(lines[0]), &child)
+		json.Unmarshal([]byte(lines[1]), &server)
+
+		if server["name"] != "GET /users/{id}" || server["parent_id"] != "00f067aa0ba902b7" || server["span_id"] != parentID {
+			t.Errorf("request span: got %v", server)
+		}
+		if attrs, _ := server["attributes"].(map[string]any); attrs["http.route"] != "/users/{id}" || attrs["http.response.status_code"] != float64(200) {
+			t.Errorf("request span attributes: got %v", server["attributes"])
+		}
+		if child["name"] != "load user" || child["parent_id"] != parentID || child["error"] != "not found" {
+			t.Errorf("child span: got %v", child)
+		}
+	})
+
+	t.Run("records status code sent by ErrorHandler", func(t *testing.T) {
+		exported.Reset()
+
+		r := ivy.NewRouter()
+		r.ErrorHandler = func(c *ivy.Context, err error) {
+			c.SendStatus(http.StatusTeapot)
+		}
+		r.Use(Tracing(TracingOptions{Exporter: NewJSONExporter(&exported)}))
+		r.Get("/fail", func(c *ivy.Context) error { return errors.New("failed") })
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+		var server map[string]any
+		json.Unmarshal(exported.Bytes(), &server)
+		if attrs, _ := server["attributes"].(map[string]any); attrs["http.response.status_code"] != float64(http.StatusTeapot) || server["error"] != "failed" {
+			t.Errorf("request span: got %v", server)
+		}
+	})
+
+	t.Run("starts a new trace, on invalid traceparent", func(t *testing.T) {
+		exported.Reset()
+
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		req.Header.Set("traceparent", "00-00000000000000000000000000000000-00f067aa0ba902b7-01")
+		req.Header.Set("tracestate", "vendor=abc")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		traceID, _, _, ok := parseTraceparent(downstream.Get("traceparent"))
+		if !ok || traceID == "00000000000000000000000000000000" {
+			t.Errorf("downstream traceparent: got %q", downstream.Get("traceparent"))
+		}
+		if downstream.Get("tracestate") != "" {
+			t.Errorf("tracestate of an invalid trace must be dropped, got %q", downstream.Get("tracestate"))
+		}
+	})
+}
+
+func TestParseTraceparent(t *testing.T) {
+	tests := map[string]bool{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01":        true,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00":        true,
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future": true,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra":  false,
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01":        false,
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01":        false,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01":        false,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7":           false,
+		"": false,
+	}
+
+	for h, want := range tests {
+		if _, _, _, ok := parseTraceparent(h); ok != want {
+			t.Errorf("%q: got %v, want %v", h, ok, want)
+		}
+	}
+}
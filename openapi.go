@@ -0,0 +1,365 @@
+package ivy
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nxtcoder17/ivy/internal/yaml"
+)
+
+// OpenAPIVersion is the version of OpenAPI specification, that [Router.OpenAPI] generates documents for
+const OpenAPIVersion = "3.1.0"
+
+// OpenAPIDocument is an [OpenAPI](https://spec.openapis.org/oas/v3.1.0) document, as generated by [Router.OpenAPI]
+type OpenAPIDocument struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       OpenAPIInfo                `json:"info"`
+	Paths      map[string]OpenAPIPathItem `json:"paths"`
+	Components *OpenAPIComponents         `json:"components,omitempty"`
+}
+
+// OpenAPIInfo is the metadata about the API
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIPathItem holds operations of a path, keyed by lowercase http method
+type OpenAPIPathItem map[string]*OpenAPIOperation
+
+// OpenAPIOperation describes a single route
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId,omitempty"`
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIParameter is a path, query, header or cookie parameter of an operation
+type OpenAPIParameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// OpenAPIRequestBody describes request body of an operation, keyed by media type
+type OpenAPIRequestBody struct {
+	Description string                       `json:"description,omitempty"`
+	Required    bool                         `json:"required,omitempty"`
+	Content     map[string]*OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIMediaType holds schema of a request or response body
+type OpenAPIMediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// OpenAPIResponse describes a response of an operation
+type OpenAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIComponents holds reusable schemas, that are referred to with `#/components/schemas/<name>`
+type OpenAPIComponents struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// openAPIMethods are the http methods, that OpenAPI path items can describe
+var openAPIMethods = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace,
+}
+
+// OpenAPI generates OpenAPI document for all the routes of the router (including the mounted ones).
+//
+//   - path params are read from route patterns, with their constraints as schema patterns
+//   - summary, description, tags and name (as operationId) are taken from the route, see [Route.Summary]
+//   - request and response schemas are reflected from request and response types of routes (see [Route.Types] and [TypedRoute]),
+//     with `json`, bind (`path`, `query`, `header`, `cookie`, `form`) and `validate` struct tags
+//
+// Routes registered without a method (like with Handle, ServeDir, or mounted non ivy handlers) are left out.
+func (r *Router) OpenAPI(info OpenAPIInfo) *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info:    info,
+		Paths:   make(map[string]OpenAPIPathItem),
+	}
+
+	sg := &schemaGenerator{schemas: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+
+	for _, route := range r.Routes() {
+		if !slices.Contains(openAPIMethods, route.Method) {
+			continue
+		}
+
+		path, params := openAPIPath(route.Pattern)
+		op := sg.operation(route, params)
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = make(OpenAPIPathItem, 1)
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = op
+	}
+
+	if len(sg.schemas) > 0 {
+		doc.Components = &OpenAPIComponents{Schemas: sg.schemas}
+	}
+
+	return doc
+}
+
+// ServeOpenAPI registers a GET route on path, that serves OpenAPI document of the whole application (starting from the top most router).
+// Document is served as YAML, when path ends with `.yaml` or `.yml`, or when requested with `?format=yaml` or an `Accept` header asking for yaml,
+// otherwise as JSON
+//
+// Example:
+//
+//	r.ServeOpenAPI("/openapi.json", ivy.OpenAPIInfo{Title: "Users API", Version: "1.0.0"})
+func (r *Router) ServeOpenAPI(path string, info ...OpenAPIInfo) *Route {
+	docInfo := OpenAPIInfo{Title: "API", Version: "0.0.0"}
+	if len(info) > 0 {
+		docInfo = info[0]
+	}
+
+	asYAML := strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")
+
+	return r.Get(path, func(c *Context) error {
+		// INFO: document is generated on every request, so that it includes routes registered after this one
+		doc := r.root().OpenAPI(docInfo)
+
+		if asYAML || c.QueryParam("format") == "yaml" || strings.Contains(c.GetHeaders().Get("Accept"), "yaml") {
+			b, err := yaml.Marshal(doc)
+			if err != nil {
+				return err
+			}
+			c.SetHeader("Content-Type", "application/yaml")
+			_, err = c.Write(b)
+			return err
+		}
+
+		return c.SendJSON(doc)
+	})
+}
+
+// openAPIPath converts a http.ServeMux pattern into an OpenAPI path, and returns names of its path params.
+// e.g. `/files/{path...}` becomes `/files/{path}`, and `/{$}` becomes `/`
+func openAPIPath(pattern string) (string, []string) {
+	if i := strings.IndexByte(pattern, '/'); i > 0 {
+		// INFO: drops host from patterns like `example.com/users`
+		pattern = pattern[i:]
+	}
+
+	var params []string
+	var sb strings.Builder
+
+	for {
+		start := strings.IndexByte(pattern, '{')
+		end := strings.IndexByte(pattern, '}')
+		if start == -1 || end < start {
+			sb.WriteString(pattern)
+			return sb.String(), params
+		}
+
+		sb.WriteString(pattern[:start])
+		name := strings.TrimSuffix(pattern[start+1:end], "...")
+		pattern = pattern[end+1:]
+
+		if name == "$" {
+			continue
+		}
+
+		params = append(params, name)
+		sb.WriteString("{" + name + "}")
+	}
+}
+
+func (sg *schemaGenerator) operation(route RouteInfo, pathParams []string) *OpenAPIOperation {
+	op := &OpenAPIOperation{
+		OperationID: route.Name,
+		Summary:     route.Summary,
+		Description: route.Description,
+		Tags:        route.Tags,
+		Responses:   make(map[string]*OpenAPIResponse, 1),
+	}
+
+	var reqParams []*OpenAPIParameter
+	var body, form *Schema
+
+	if route.Request != nil {
+		reqParams, body, form = sg.requestSchemas(route.Request)
+	}
+
+	for _, name := range pathParams {
+		param := &OpenAPIParameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: SchemaTypes{"string"}}}
+
+		if i := slices.IndexFunc(reqParams, func(p *OpenAPIParameter) bool { return p.In == "path" && p.Name == name }); i != -1 {
+			param.Schema = reqParams[i].Schema
+			reqParams = slices.Delete(reqParams, i, i+1)
+		}
+
+		if re, ok := route.Constraints[name]; ok && param.Schema.Ref == "" {
+			param.Schema.Pattern = re
+		}
+
+		op.Parameters = append(op.Parameters, param)
+	}
+
+	for _, param := range reqParams {
+		// INFO: path params, that are not in the route pattern can never be set
+		if param.In != "path" {
+			op.Parameters = append(op.Parameters, param)
+		}
+	}
+
+	if route.Method != http.MethodGet && route.Method != http.MethodHead && (body != nil || form != nil) {
+		op.RequestBody = &OpenAPIRequestBody{Required: true, Content: make(map[string]*OpenAPIMediaType, 1)}
+		if body != nil {
+			op.RequestBody.Content[MediaTypeJSON] = &OpenAPIMediaType{Schema: body}
+		}
+		if form != nil {
+			op.RequestBody.Content[MediaTypeForm] = &OpenAPIMediaType{Schema: form}
+		}
+	}
+
+	if route.Response == nil {
+		op.Responses["200"] = &OpenAPIResponse{Description: http.StatusText(http.StatusOK)}
+		return op
+	}
+
+	code := responseStatusCode(route.Response)
+	resp := &OpenAPIResponse{Description: http.StatusText(code)}
+	if code != http.StatusNoContent && code != http.StatusNotModified {
+		resp.Content = map[string]*OpenAPIMediaType{MediaTypeJSON: {Schema: sg.schema(route.Response)}}
+	}
+	op.Responses[strconv.Itoa(code)] = resp
+
+	return op
+}
+
+// responseStatusCode returns status code, that the zero value of a Typed handler response type reports with [StatusCoder]
+func responseStatusCode(t reflect.Type) (code int) {
+	defer func() {
+		if recover() != nil || code == 0 {
+			code = http.StatusOK
+		}
+	}()
+
+	v := reflect.New(t).Elem()
+	if t.Kind() == reflect.Pointer {
+		v = reflect.New(t.Elem())
+	}
+
+	if sc, ok := v.Interface().(StatusCoder); ok {
+		return sc.StatusCode()
+	}
+	return http.StatusOK
+}
+
+// requestSchemas splits a Typed handler request type into parameters (for fields with path, query, header and cookie tags),
+// json body schema, and form body schema (for fields with form tags)
+func (sg *schemaGenerator) requestSchemas(t reflect.Type) (params []*OpenAPIParameter, body *Schema, form *Schema) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || !hasBindFields(t) {
+		return nil, sg.schema(t), nil
+	}
+
+	body = &Schema{Type: SchemaTypes{"object"}}
+	form = &Schema{Type: SchemaTypes{"object"}}
+	sg.requestFields(t, &params, body, form)
+
+	if len(body.Properties) == 0 {
+		body = nil
+	}
+	if len(form.Properties) == 0 {
+		form = nil
+	}
+
+	return params, body, form
+}
+
+func (sg *schemaGenerator) requestFields(t reflect.Type, params *[]*OpenAPIParameter, body *Schema, form *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		source, name := bindTag(sf)
+		if source == "" {
+			ft := sf.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if sf.Anonymous && ft.Kind() == reflect.Struct && sf.Tag.Get("json") == "" {
+				sg.requestFields(ft, params, body, form)
+				continue
+			}
+
+			sg.addProperty(body, sf)
+			continue
+		}
+
+		rules := sf.Tag.Get("validate")
+		schema := sg.fieldSchema(sf.Type, rules)
+		if d, ok := sf.Tag.Lookup("default"); ok {
+			schema.Default = enumValue(sf.Type, d)
+		}
+
+		if source == "form" {
+			if form.Properties == nil {
+				form.Properties = make(map[string]*Schema)
+			}
+			form.Properties[name] = schema
+			if hasRule(rules, "required") {
+				form.Required = append(form.Required, name)
+			}
+			continue
+		}
+
+		*params = append(*params, &OpenAPIParameter{
+			Name:     name,
+			In:       source,
+			Required: source == "path" || hasRule(rules, "required"),
+			Schema:   schema,
+		})
+	}
+}
+
+func hasBindFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if hasBindTag(sf) {
+			return true
+		}
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && ft.Kind() == reflect.Struct && hasBindFields(ft) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRule(rules string, rule string) bool {
+	for _, r := range strings.Split(rules, ",") {
+		if name, _, _ := strings.Cut(r, "="); name == rule {
+			return true
+		}
+	}
+	return false
+}
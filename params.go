@@ -0,0 +1,166 @@
+package ivy
+
+import (
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// PathParamInt parses path param `key` as an int
+// On failure, it returns a [HTTPError] with status code 400
+func (c *Context) PathParamInt(key string) (int, error) {
+	return PathParamAs[int](c, key)
+}
+
+// PathParamInt64 parses path param `key` as an int64
+// On failure, it returns a [HTTPError] with status code 400
+func (c *Context) PathParamInt64(key string) (int64, error) {
+	return PathParamAs[int64](c, key)
+}
+
+// PathParamUUID parses path param `key` as an [UUID]
+// On failure, it returns a [HTTPError] with status code 400
+func (c *Context) PathParamUUID(key string) (UUID, error) {
+	return PathParamAs[UUID](c, key)
+}
+
+// PathParamTime parses path param `key` as a [time.Time], with layout defaulting to [time.RFC3339]
+// On failure, it returns a [HTTPError] with status code 400
+func (c *Context) PathParamTime(key string, layout ...string) (time.Time, error) {
+	l := time.RFC3339
+	if len(layout) > 0 {
+		l = layout[0]
+	}
+
+	t, err := time.Parse(l, c.PathParam(key))
+	if err != nil {
+		return time.Time{}, invalidPathParam(key, err)
+	}
+	return t, nil
+}
+
+// PathParamAs parses path param `key` into T, where T is either an [encoding.TextUnmarshaler],
+// or has an underlying type of string, bool, int, uint or float kinds
+// On failure, it returns a [HTTPError] with status code 400
+//
+// Example:
+//
+//	id, err := ivy.PathParamAs[uint32](c, "id")
+func PathParamAs[T any](c *Context, key string) (T, error) {
+	var v T
+	if err := parseValue(c.PathParam(key), reflect.ValueOf(&v).Elem()); err != nil {
+		return v, invalidPathParam(key, err)
+	}
+	return v, nil
+}
+
+func invalidPathParam(key string, err error) HTTPError {
+	return NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid path param %q: %v", key, err))
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// parseValue parses string s into v, which must be settable
+func parseValue(s string, v reflect.Value) error {
+	if reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeFor[time.Duration]() {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return unwrapNumError(err)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return unwrapNumError(err)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return unwrapNumError(err)
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+// unwrapNumError drops the `strconv.ParseInt: parsing "abc":` part, as callers already mention the key
+func unwrapNumError(err error) error {
+	if ne, ok := err.(*strconv.NumError); ok {
+		return fmt.Errorf("%q is %w", ne.Num, ne.Err)
+	}
+	return err
+}
+
+// UUID is a 16 byte universally unique identifier, in its canonical textual form it looks like `xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx`
+type UUID [16]byte
+
+// ParseUUID parses canonical textual form of an UUID
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, fmt.Errorf("%q is not a valid UUID", s)
+	}
+
+	b := []byte(s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:36])
+	if _, err := hex.Decode(u[:], b); err != nil {
+		return u, fmt.Errorf("%q is not a valid UUID", s)
+	}
+
+	return u, nil
+}
+
+// String implements fmt.Stringer.
+func (u UUID) String() string {
+	h := hex.EncodeToString(u[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (u *UUID) UnmarshalText(b []byte) error {
+	v, err := ParseUUID(string(b))
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
+
+var (
+	_ encoding.TextMarshaler   = UUID{}
+	_ encoding.TextUnmarshaler = (*UUID)(nil)
+)
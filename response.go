@@ -0,0 +1,181 @@
+package ivy
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// ResponseWriter wraps a http.ResponseWriter, to track the state of response (see [ResponseWriter.Committed]),
+// while still reaching every optional interface of the underlying writer, like [http.Flusher], [http.Hijacker], [io.ReaderFrom] and [http.Pusher].
+// When the underlying writer does not support one of them, it fails with [http.ErrNotSupported], like [http.ResponseController] does.
+//
+// Context writes responses through a ResponseWriter. Middlewares replacing the response writer (with [Context.SetResponseWriter])
+// can wrap their own writer with it, so that optional interfaces keep working through it.
+// Their writer must implement `Unwrap() http.ResponseWriter`, for interfaces it does not implement itself to be found.
+//
+// Example:
+//
+//	type gzipWriter struct {
+//	    http.ResponseWriter
+//	    zw *gzip.Writer
+//	}
+//
+//	func (w *gzipWriter) Write(b []byte) (int, error)    { return w.zw.Write(b) }
+//	func (w *gzipWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+//
+//	c.SetResponseWriter(ivy.NewResponseWriter(&gzipWriter{ResponseWriter: c.ResponseWriter(), zw: zw}))
+type ResponseWriter struct {
+	w http.ResponseWriter
+
+	status    int
+	written   int64
+	committed bool
+}
+
+// NewResponseWriter wraps w
+func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	return &ResponseWriter{w: w}
+}
+
+// Header implements http.ResponseWriter.
+func (rw *ResponseWriter) Header() http.Header {
+	return rw.w.Header()
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (rw *ResponseWriter) WriteHeader(code int) {
+	if rw.committed {
+		// INFO: dropping it here, instead of letting net/http log "superfluous response.WriteHeader call"
+		return
+	}
+
+	rw.w.WriteHeader(code)
+
+	// informational responses (like 103 Early Hints) can be followed by the final one, except for 101 Switching Protocols
+	if code >= 200 || code == http.StatusSwitchingProtocols {
+		rw.status = code
+		rw.committed = true
+	}
+}
+
+// Write implements http.ResponseWriter.
+func (rw *ResponseWriter) Write(b []byte) (int, error) {
+	if !rw.committed {
+		rw.WriteHeader(http.StatusOK)
+	}
+
+	n, err := rw.w.Write(b)
+	rw.written += int64(n)
+	return n, err
+}
+
+// ReadFrom implements io.ReaderFrom, so that io.Copy can use the underlying writer's ReadFrom (like sendfile for files)
+func (rw *ResponseWriter) ReadFrom(r io.Reader) (int64, error) {
+	if !rw.committed {
+		rw.WriteHeader(http.StatusOK)
+	}
+
+	var n int64
+	var err error
+	if rf, ok := rw.w.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		// INFO: writerOnly hides ReadFrom of rw, otherwise io.Copy would call it again
+		n, err = io.Copy(writerOnly{rw.w}, r)
+	}
+
+	rw.written += n
+	return n, err
+}
+
+type writerOnly struct {
+	io.Writer
+}
+
+// Flush implements http.Flusher.
+func (rw *ResponseWriter) Flush() {
+	rw.FlushError()
+}
+
+// FlushError flushes buffered data to the client, it is what [http.ResponseController.Flush] calls
+func (rw *ResponseWriter) FlushError() error {
+	if !rw.committed {
+		rw.WriteHeader(http.StatusOK)
+	}
+	return http.NewResponseController(rw.w).Flush()
+}
+
+// Hijack implements http.Hijacker. Once hijacked, response is committed, and its status is reported as 101 Switching Protocols
+func (rw *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(rw.w).Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !rw.committed {
+		rw.status = http.StatusSwitchingProtocols
+		rw.committed = true
+	}
+	return conn, brw, nil
+}
+
+// Push implements http.Pusher.
+func (rw *ResponseWriter) Push(target string, opts *http.PushOptions) error {
+	for w := rw.w; w != nil; {
+		if p, ok := w.(http.Pusher); ok {
+			return p.Push(target, opts)
+		}
+
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		w = u.Unwrap()
+	}
+	return http.ErrNotSupported
+}
+
+// Unwrap returns the underlying writer, it is used by [http.ResponseController] to reach deadlines and such
+func (rw *ResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.w
+}
+
+// Committed reports whether response status and headers have been written, after which they can no longer be changed
+func (rw *ResponseWriter) Committed() bool {
+	return rw.committed
+}
+
+// StatusCode returns status code of the response, it is 0 until the response is committed
+func (rw *ResponseWriter) StatusCode() int {
+	return rw.status
+}
+
+// BytesWritten returns number of bytes of response body, that have been written so far
+func (rw *ResponseWriter) BytesWritten() int64 {
+	return rw.written
+}
+
+var (
+	_ http.ResponseWriter = (*ResponseWriter)(nil)
+	_ http.Flusher        = (*ResponseWriter)(nil)
+	_ http.Hijacker       = (*ResponseWriter)(nil)
+	_ http.Pusher         = (*ResponseWriter)(nil)
+	_ io.ReaderFrom       = (*ResponseWriter)(nil)
+)
+
+// Committed reports whether response status and headers have been written, after which they can no longer be changed
+func (c *Context) Committed() bool {
+	return c.writer.Committed()
+}
+
+// StatusCode returns status code of the response, it is 0 until the response is committed
+func (c *Context) StatusCode() int {
+	return c.writer.StatusCode()
+}
+
+// BytesWritten returns number of bytes of response body, that have been written so far
+func (c *Context) BytesWritten() int64 {
+	return c.writer.BytesWritten()
+}
@@ -0,0 +1,239 @@
+package ivy
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Route is returned when registering a handler with methods like Get, Post etc.
+// It allows attaching extra information to that route
+//
+// Example:
+//
+//	r.Get("/users/{id}", handler).Name("user.show")
+type Route struct {
+	method  string
+	pattern string
+	name    string
+	tags    []string
+
+	// summary and description are only used for documentation, see [Router.OpenAPI]
+	summary     string
+	description string
+
+	// constraints on path wildcards, like `{id:int}`
+	constraints map[string]*regexp.Regexp
+
+	// maxBodySize overrides router's MaxBodySize, see [Route.MaxBodySize]
+	maxBodySize int64
+
+	// timeout of the route's handler chain, see [Route.Timeout]
+	timeout time.Duration
+
+	// names of the route handlers, and count of middlewares that run before them
+	handlers    []string
+	middlewares int
+
+	// request and response types of the route's handler, see [Route.Types]
+	request  reflect.Type
+	response reflect.Type
+
+	router *Router
+
+	// mounted is set, when this route is where another ivy.Router is mounted
+	mounted *Router
+}
+
+type mountPoint struct {
+	prefix string
+	parent *Router
+}
+
+// Name sets a name on the route, which can later be used to build its URL with [Router.URL] or [Context.URLFor]
+func (rt *Route) Name(name string) *Route {
+	if rt.router != nil {
+		if other := rt.router.base().lookupRoute(name); other != nil && other != rt {
+			panic(fmt.Sprintf("ivy: route name %q is already registered for %s %s", name, other.method, other.pattern))
+		}
+	}
+
+	rt.name = name
+	return rt
+}
+
+// Tags adds tags on the route, they are only used for introspection, see [Router.Routes]
+func (rt *Route) Tags(tags ...string) *Route {
+	rt.tags = append(rt.tags, tags...)
+	return rt
+}
+
+// Summary sets a short summary of the route, it is used for the operation summary in [Router.OpenAPI]
+func (rt *Route) Summary(summary string) *Route {
+	rt.summary = summary
+	return rt
+}
+
+// Description sets a longer description of the route, it is used for the operation description in [Router.OpenAPI]
+func (rt *Route) Description(description string) *Route {
+	rt.description = description
+	return rt
+}
+
+// MaxBodySize limits size of request bodies on this route to n bytes, overriding router's MaxBodySize, a negative n removes the limit
+//
+// Example:
+//
+//	r.Post("/uploads", uploadHandler).MaxBodySize(100 << 20)
+func (rt *Route) MaxBodySize(n int64) *Route {
+	rt.maxBodySize = n
+	return rt
+}
+
+// Timeout cancels request context of the route's handlers (including router's middlewares) after d, and responds with
+// router's ErrorHandler, if they have not returned by then. See [Context.NextWithTimeout] for how the response is handled
+//
+// Example:
+//
+//	r.Get("/reports/{id}", reportHandler).Timeout(5 * time.Second)
+func (rt *Route) Timeout(d time.Duration) *Route {
+	rt.timeout = d
+	return rt
+}
+
+// Types records request and response types of the route's handler, they are used for documentation, see [Router.OpenAPI].
+// A nil type is left out. [TypedRoute] sets them for [Typed] handlers
+//
+// Example:
+//
+//	r.Post("/uploads", uploadHandler).Types(reflect.TypeFor[UploadRequest](), reflect.TypeFor[Upload]())
+func (rt *Route) Types(request reflect.Type, response reflect.Type) *Route {
+	rt.request, rt.response = request, response
+	return rt
+}
+
+// Method returns http method of the route
+func (rt *Route) Method() string {
+	return rt.method
+}
+
+// Pattern returns path pattern of the route, including the group prefix
+func (rt *Route) Pattern() string {
+	return rt.pattern
+}
+
+func (r *Router) lookupRoute(name string) *Route {
+	for _, route := range r.routes {
+		if route.mounted == nil && route.name == name {
+			return route
+		}
+	}
+	return nil
+}
+
+// URL builds URL path for the route named `name`, with path wildcards replaced by params
+// params are key-value pairs, like `r.URL("user.show", "id", 1)`
+//
+// Routes in routers mounted with Mount are also looked up, and their URL path includes the mount prefix.
+func (r *Router) URL(name string, params ...any) (string, error) {
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("ivy: route %q, params must be key-value pairs", name)
+	}
+
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		key, ok := params[i].(string)
+		if !ok {
+			return "", fmt.Errorf("ivy: route %q, param key must be a string, got %T", name, params[i])
+		}
+		values[key] = fmt.Sprint(params[i+1])
+	}
+
+	prefix, route := r.base().findRoute(name)
+	if route == nil {
+		return "", fmt.Errorf("ivy: no route named %q", name)
+	}
+
+	for key, re := range route.constraints {
+		if v, ok := values[key]; ok && !re.MatchString(v) {
+			return "", fmt.Errorf("ivy: route %q, path param %q (= %q) does not satisfy its constraint", name, key, v)
+		}
+	}
+
+	path, err := buildPath(route.pattern, values)
+	if err != nil {
+		return "", fmt.Errorf("ivy: route %q, %w", name, err)
+	}
+
+	return prefix + path, nil
+}
+
+// findRoute looks for route named `name` in the router, and then in the mounted routers.
+// It returns the mount prefix, of the router that route is found on
+func (r *Router) findRoute(name string) (string, *Route) {
+	if route := r.lookupRoute(name); route != nil {
+		return "", route
+	}
+
+	for _, mount := range r.routes {
+		if mount.mounted == nil {
+			continue
+		}
+
+		if prefix, route := mount.mounted.findRoute(name); route != nil {
+			return strings.TrimSuffix(mount.pattern, "/") + prefix, route
+		}
+	}
+
+	return "", nil
+}
+
+// buildPath substitutes wildcards like `{id}`, `{path...}` and `{$}` in a http.ServeMux path pattern
+func buildPath(pattern string, values map[string]string) (string, error) {
+	var sb strings.Builder
+	sb.Grow(len(pattern))
+
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start == -1 {
+			sb.WriteString(pattern)
+			return sb.String(), nil
+		}
+
+		end := strings.IndexByte(pattern[start:], '}')
+		if end == -1 {
+			return "", fmt.Errorf("bad wildcard in pattern %q", pattern)
+		}
+		end += start
+
+		sb.WriteString(pattern[:start])
+
+		wildcard := pattern[start+1 : end]
+		pattern = pattern[end+1:]
+
+		if wildcard == "$" {
+			continue
+		}
+
+		name, multi := strings.CutSuffix(wildcard, "...")
+
+		v, ok := values[name]
+		if !ok {
+			return "", fmt.Errorf("missing path param %q", name)
+		}
+
+		if !multi {
+			sb.WriteString(url.PathEscape(v))
+			continue
+		}
+
+		segments := strings.Split(v, "/")
+		for i := range segments {
+			segments[i] = url.PathEscape(segments[i])
+		}
+		sb.WriteString(strings.Join(segments, "/"))
+	}
+}
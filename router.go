@@ -1,27 +1,74 @@
 package ivy
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/fs"
 	"log/slog"
 	"net/http"
 	"strings"
+	"sync/atomic"
 )
 
 type Router struct {
 	mux         *http.ServeMux
 	middlewares []Handler
 
+	// prefix and parent are only set for route groups (see [Router.Group]),
+	// which share their parent's mux, but carry their own middleware stack
+	prefix string
+	parent *Router
+
+	// routes are only tracked on the base router, i.e. groups register their routes on it
+	routes []*Route
+
+	// mountedAt is set, when this router is mounted into another router
+	mountedAt *mountPoint
+
+	// fallback handlers, see [Router.NotFound] and [Router.MethodNotAllowed]
+	notFound         Handler
+	methodNotAllowed Handler
+
+	// catchAll is the catch-all route without method, that the fallback route hands unmatched requests over to, see [Router.handle]
+	catchAll atomic.Pointer[catchAllRoute]
+
 	ErrorHandler ErrorHandler
+
+	// Codecs are used by Context.Send and Context.ParseBodyInto, when nil, codecs of parent router are used,
+	// and if none of them has any, builtin codecs from NewCodecs() are used
+	Codecs *Codecs
+
+	// MaxBodySize limits size of request bodies (in bytes) with http.MaxBytesReader, reading past it fails with [*http.MaxBytesError],
+	// which Context.ParseBodyInto (and DefaultErrorHandler) turn into 413 Request Entity Too Large.
+	// When 0, limit of parent router is used, and a negative value means no limit. Routes can override it, see [Route.MaxBodySize]
+	MaxBodySize int64
 }
 
 var DefaultErrorHandler ErrorHandler = func(c *Context, err error) {
+	if c.Committed() {
+		// INFO: response is already on its way, so the error can only be logged
+		c.Logger.Error("error after response was committed", "err", err, "status", c.StatusCode())
+		return
+	}
+
 	var httpError HTTPError
 	if errors.As(err, &httpError) {
 		http.Error(c.ResponseWriter(), err.Error(), httpError.Code())
 		return
 	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		http.Error(c.ResponseWriter(), http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		http.Error(c.ResponseWriter(), err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
 	http.Error(c.ResponseWriter(), err.Error(), http.StatusInternalServerError)
 }
 
@@ -49,7 +96,12 @@ type Handler func(c *Context) error
 
 // ServeHTTP implements http.Handler.
 func (hf Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	hf(newContext(r, w))
+	c := newContext(r, w)
+	func() {
+		defer c.runCleanups()
+		hf(c)
+	}()
+	c.runAfterResponse()
 }
 
 var _ http.Handler = (Handler)(nil)
@@ -58,8 +110,15 @@ var _ http.Handler = (Handler)(nil)
 // Execution: each handler calls c.Next() to invoke next in chain (like Express's next()).
 // Uses index-based traversal - c.Next() increments handlerIdx, boundary check prevents overflow when final handler calls Next().
 func (r *Router) chainHandlers(handlers ...Handler) http.HandlerFunc {
-	allHandlers := make([]Handler, 0, len(r.middlewares)+len(handlers))
-	allHandlers = append(allHandlers, r.middlewares...)
+	return r.chainRouteHandlers(nil, handlers...)
+}
+
+// chainRouteHandlers is chainHandlers for handlers of route, that may override router's settings (like MaxBodySize)
+func (r *Router) chainRouteHandlers(route *Route, handlers ...Handler) http.HandlerFunc {
+	middlewares := r.allMiddlewares()
+
+	allHandlers := make([]Handler, 0, len(middlewares)+len(handlers))
+	allHandlers = append(allHandlers, middlewares...)
 	allHandlers = append(allHandlers, handlers...)
 
 	next := func(c *Context) error {
@@ -71,78 +130,284 @@ func (r *Router) chainHandlers(handlers ...Handler) http.HandlerFunc {
 	}
 
 	return func(w http.ResponseWriter, req *http.Request) {
+		// INFO: limit is looked up per request, as it can be set on the router (or route) after routes are registered.
+		// A mounted router applies it itself, once it has matched a route, that may override it
+		if route == nil || route.mounted == nil {
+			if limit := r.bodyLimit(route); limit > 0 && req.Body != nil && req.Body != http.NoBody {
+				req.Body = http.MaxBytesReader(w, req.Body, limit)
+			}
+		}
+
 		ctx := newContext(req, w)
 		ctx.next = next
-
-		if err := next(ctx); err != nil {
-			if r.ErrorHandler != nil {
-				r.ErrorHandler(ctx, err)
-				return
+		ctx.router = r
+		ctx.pattern.value = r.fullPattern(req.Pattern)
+
+		// INFO: cleanups (like stopping SSE heartbeats) must run before ErrorHandler gets to write the response
+		err := func() error {
+			defer ctx.runCleanups()
+			if route != nil && route.timeout > 0 {
+				return ctx.runWithTimeout(route.timeout, next)
 			}
-			DefaultErrorHandler(ctx, err)
+			return next(ctx)
+		}()
+
+		if err != nil {
+			r.errorHandler()(ctx, err)
+		}
+		ctx.runAfterResponse()
+	}
+}
+
+// allMiddlewares returns middlewares of all the parent groups, followed by the router's own middlewares
+func (r *Router) allMiddlewares() []Handler {
+	if r.parent == nil {
+		return r.middlewares
+	}
+
+	parentMiddlewares := r.parent.allMiddlewares()
+
+	middlewares := make([]Handler, 0, len(parentMiddlewares)+len(r.middlewares))
+	middlewares = append(middlewares, parentMiddlewares...)
+	return append(middlewares, r.middlewares...)
+}
+
+// bodyLimit returns MaxBodySize of route, or else the nearest MaxBodySize, looking up through parent groups and mount points
+func (r *Router) bodyLimit(route *Route) int64 {
+	if route != nil && route.maxBodySize != 0 {
+		return route.maxBodySize
+	}
+
+	for router := r; router != nil; {
+		if router.MaxBodySize != 0 {
+			return router.MaxBodySize
 		}
+
+		switch {
+		case router.parent != nil:
+			router = router.parent
+		case router.mountedAt != nil:
+			router = router.mountedAt.parent
+		default:
+			router = nil
+		}
+	}
+	return 0
+}
+
+// errorHandler returns the nearest ErrorHandler, looking up through parent groups and mount points
+func (r *Router) errorHandler() ErrorHandler {
+	for router := r; router != nil; {
+		if router.ErrorHandler != nil {
+			return router.ErrorHandler
+		}
+
+		switch {
+		case router.parent != nil:
+			router = router.parent
+		case router.mountedAt != nil:
+			router = router.mountedAt.parent
+		default:
+			router = nil
+		}
+	}
+
+	return func(c *Context, err error) {
+		DefaultErrorHandler(c, err)
+	}
+}
+
+// withPrefix prepends group prefix to the path of a http.ServeMux pattern like `GET /path` or `/path`
+func (r *Router) withPrefix(pattern string) string {
+	if r.prefix == "" {
+		return pattern
+	}
+
+	if method, path, ok := strings.Cut(pattern, " "); ok {
+		return method + " " + r.prefix + strings.TrimLeft(path, " ")
 	}
+
+	return r.prefix + pattern
 }
 
-func (r *Router) register(method string, path string, handlers ...Handler) {
+// fullPattern prepends paths, that the router is mounted at, to the path of a http.ServeMux pattern
+func (r *Router) fullPattern(pattern string) string {
+	if pattern == "" {
+		return ""
+	}
+
+	var prefix string
+	for m := r.base().mountedAt; m != nil; m = m.parent.base().mountedAt {
+		prefix = m.prefix + prefix
+	}
+	if prefix == "" {
+		return pattern
+	}
+
+	if method, path, ok := strings.Cut(pattern, " "); ok {
+		return method + " " + prefix + strings.TrimLeft(path, " ")
+	}
+	return prefix + pattern
+}
+
+// base returns the router, that a group (or nested groups) belong to
+func (r *Router) base() *Router {
+	for r.parent != nil {
+		r = r.parent
+	}
+	return r
+}
+
+// root returns the top most router, looking up through groups and mount points
+func (r *Router) root() *Router {
+	r = r.base()
+	for r.mountedAt != nil {
+		r = r.mountedAt.parent.base()
+	}
+	return r
+}
+
+func (r *Router) register(method string, path string, handlers ...Handler) *Route {
+	pattern, constraints, err := parseConstraints(r.withPrefix(path))
+	if err != nil {
+		panic(fmt.Sprintf("ivy: %s %s: %v", method, path, err))
+	}
+
 	if handlers == nil {
-		return
+		return &Route{method: method, pattern: pattern, constraints: constraints}
+	}
+
+	route := r.addRoute(method, pattern, handlerNames(handlers...)...)
+	route.constraints = constraints
+
+	h := r.chainRouteHandlers(route, handlers...)
+	if constraints != nil {
+		h = r.withConstraints(constraints, h)
+	}
+
+	r.mux.HandleFunc(method+" "+route.pattern, h)
+	return route
+}
+
+// addRoute records a route on the base router, for route introspection and reverse URL lookups
+func (r *Router) addRoute(method string, pattern string, handlers ...string) *Route {
+	route := &Route{
+		method:      method,
+		pattern:     pattern,
+		handlers:    handlers,
+		middlewares: len(r.allMiddlewares()),
+		router:      r,
 	}
 
-	r.mux.HandleFunc(method+" "+path, r.chainHandlers(handlers...))
+	base := r.base()
+	base.routes = append(base.routes, route)
+	return route
 }
 
-func (r *Router) Get(path string, handlers ...Handler) {
-	r.register(http.MethodGet, path, handlers...)
+func (r *Router) Get(path string, handlers ...Handler) *Route {
+	return r.register(http.MethodGet, path, handlers...)
 }
 
-func (r *Router) Post(path string, handlers ...Handler) {
-	r.register(http.MethodPost, path, handlers...)
+func (r *Router) Post(path string, handlers ...Handler) *Route {
+	return r.register(http.MethodPost, path, handlers...)
 }
 
-func (r *Router) Put(path string, handlers ...Handler) {
-	r.register(http.MethodPut, path, handlers...)
+func (r *Router) Put(path string, handlers ...Handler) *Route {
+	return r.register(http.MethodPut, path, handlers...)
 }
 
-func (r *Router) Delete(path string, handlers ...Handler) {
-	r.register(http.MethodDelete, path, handlers...)
+func (r *Router) Delete(path string, handlers ...Handler) *Route {
+	return r.register(http.MethodDelete, path, handlers...)
 }
 
-func (r *Router) Head(path string, handlers ...Handler) {
-	r.register(http.MethodHead, path, handlers...)
+func (r *Router) Head(path string, handlers ...Handler) *Route {
+	return r.register(http.MethodHead, path, handlers...)
 }
 
-func (r *Router) Method(method string, path string, handlers ...Handler) {
-	r.register(method, path, handlers...)
+func (r *Router) Method(method string, path string, handlers ...Handler) *Route {
+	return r.register(method, path, handlers...)
 }
 
 func (r *Router) Use(handlers ...Handler) {
 	r.middlewares = append(r.middlewares, handlers...)
 }
 
+// Route creates a route group, that registers its routes (prefixed with `prefix`) on the same underlying mux as the parent router.
+// Middlewares added on the group with Use, only apply to routes registered on the group, and run after the parent's middlewares.
+//
+// Unlike Mount, request URL path is not rewritten.
+func (r *Router) Route(prefix string) *Router {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+
+	return &Router{
+		mux:         r.mux,
+		middlewares: nil,
+		prefix:      r.prefix + prefix,
+		parent:      r,
+	}
+}
+
+// Group creates a route group with Route, and calls fn with it
+//
+// Example:
+//
+//	r.Group("/admin", func(g *ivy.Router) {
+//	    g.Use(middleware.BasicAuth("admin", creds))
+//	    g.Get("/stats", statsHandler)
+//	})
+func (r *Router) Group(prefix string, fn func(g *Router)) *Router {
+	g := r.Route(prefix)
+	fn(g)
+	return g
+}
+
 // INFO: when mouting another router / http.Handler, we need to ensure that middlewares defined on router (r), are also applied on new handlers
 
 func (r *Router) Mount(path string, h http.Handler) {
+	path = r.withPrefix(path)
 	if !strings.HasSuffix(path, "/") {
 		path = path + "/"
 	}
 
+	var route *Route
 	if anotherRouter, ok := h.(*Router); ok {
-		if anotherRouter.ErrorHandler == nil {
+		// INFO: otherwise, errors of the mounted router go to the ErrorHandler, that r falls back to, looked up when they happen
+		if anotherRouter.ErrorHandler == nil && r.ErrorHandler != nil {
 			anotherRouter.ErrorHandler = r.ErrorHandler
 		}
+
+		anotherRouter.base().mountedAt = &mountPoint{prefix: path[:len(path)-1], parent: r}
+
+		route = r.addRoute("", path)
+		route.mounted = anotherRouter.base()
+	} else {
+		route = r.addRoute("", path, handlerName(h))
 	}
 
-	r.mux.Handle(path, http.StripPrefix(path[:len(path)-1], r.chainHandlers(ToIvyHandler(h))))
-	r.mux.Handle(path[:len(path)-1], http.StripPrefix(path[:len(path)-1], r.chainHandlers(ToIvyHandler(h))))
+	r.mux.Handle(path, http.StripPrefix(path[:len(path)-1], r.chainRouteHandlers(route, ToIvyHandler(h))))
+	r.mux.Handle(path[:len(path)-1], http.StripPrefix(path[:len(path)-1], r.chainRouteHandlers(route, ToIvyHandler(h))))
 }
 
 func (r *Router) HandleFunc(path string, handle http.HandlerFunc) {
-	r.mux.HandleFunc(path, r.chainHandlers(ToIvyHandler(handle)))
+	r.addPatternRoute(r.withPrefix(path), handlerName(handle))
+	r.handle(r.withPrefix(path), r.chainHandlers(ToIvyHandler(handle)))
 }
 
 func (r *Router) Handle(path string, handler http.Handler) {
-	r.mux.Handle(path, r.chainHandlers(ToIvyHandler(handler)))
+	r.addPatternRoute(r.withPrefix(path), handlerName(handler))
+	r.handle(r.withPrefix(path), r.chainHandlers(ToIvyHandler(handler)))
+}
+
+// addPatternRoute records a route for http.ServeMux pattern, which may or may not include a method
+func (r *Router) addPatternRoute(pattern string, handler string) {
+	if method, path, ok := strings.Cut(pattern, " "); ok {
+		r.addRoute(method, strings.TrimLeft(path, " "), handler)
+		return
+	}
+	r.addRoute("", pattern, handler)
 }
 
 // ServeDir serves static files from a filesystem directory
@@ -156,6 +421,7 @@ func (r *Router) ServeDirFS(path string, fsys fs.FS) {
 }
 
 func (r *Router) serveFiles(path string, fsys http.FileSystem) {
+	path = r.withPrefix(path)
 	if !strings.HasSuffix(path, "/") {
 		path = path + "/"
 	}
@@ -163,6 +429,8 @@ func (r *Router) serveFiles(path string, fsys http.FileSystem) {
 	fileServer := http.FileServer(fsys)
 	handler := http.StripPrefix(path[:len(path)-1], fileServer)
 
+	r.addRoute("", path, handlerName(fileServer))
+
 	r.mux.Handle(path, r.chainHandlers(ToIvyHandler(handler)))
 	r.mux.Handle(path[:len(path)-1], r.chainHandlers(ToIvyHandler(handler)))
 }
@@ -171,9 +439,11 @@ var _ http.Handler = (*Router)(nil)
 
 // NewRouter() creates an ivy.Router with some defaults
 func NewRouter() *Router {
-	return &Router{
+	r := &Router{
 		mux:          http.NewServeMux(),
 		middlewares:  nil,
 		ErrorHandler: nil,
 	}
+	r.registerFallback()
+	return r
 }
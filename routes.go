@@ -0,0 +1,128 @@
+package ivy
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+)
+
+// RouteInfo describes a registered route, as returned by [Router.Routes]
+type RouteInfo struct {
+	// Method is empty for routes registered without a method, e.g. with Handle, ServeDir or Mount
+	Method string `json:"method"`
+
+	// Pattern is the full path pattern, including group and mount prefixes
+	Pattern string `json:"pattern"`
+
+	Name        string   `json:"name,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Summary     string   `json:"summary,omitempty"`
+	Description string   `json:"description,omitempty"`
+
+	// Constraints are regular expressions, that path params must match, keyed by path param name
+	Constraints map[string]string `json:"constraints,omitempty"`
+
+	// Handlers are the function names of route handlers
+	Handlers []string `json:"handlers"`
+
+	// Middlewares is the count of middlewares (added with Use), that run before route handlers
+	Middlewares int `json:"middlewares"`
+
+	// Request and Response are the types of the route's handler, if recorded with [Route.Types] (or [TypedRoute])
+	Request  reflect.Type `json:"-"`
+	Response reflect.Type `json:"-"`
+}
+
+// Routes lists all the routes registered on the router, in order of registration.
+// Routes of a mounted ivy.Router are listed in place of its mount point, with mount prefix added to their patterns.
+func (r *Router) Routes() []RouteInfo {
+	return r.base().collectRoutes("", 0)
+}
+
+func (r *Router) collectRoutes(prefix string, middlewares int) []RouteInfo {
+	routes := make([]RouteInfo, 0, len(r.routes))
+
+	for _, route := range r.routes {
+		if route.mounted != nil {
+			routes = append(routes, route.mounted.collectRoutes(prefix+strings.TrimSuffix(route.pattern, "/"), middlewares+route.middlewares)...)
+			continue
+		}
+
+		var constraints map[string]string
+		if route.constraints != nil {
+			constraints = make(map[string]string, len(route.constraints))
+			for name, re := range route.constraints {
+				constraints[name] = re.String()
+			}
+		}
+
+		routes = append(routes, RouteInfo{
+			Method:      route.method,
+			Pattern:     prefix + route.pattern,
+			Name:        route.name,
+			Tags:        route.tags,
+			Summary:     route.summary,
+			Description: route.description,
+			Constraints: constraints,
+			Handlers:    route.handlers,
+			Middlewares: middlewares + route.middlewares,
+			Request:     route.request,
+			Response:    route.response,
+		})
+	}
+
+	return routes
+}
+
+// RoutesHandler serves the route table of the whole application (starting from the top most router).
+// It responds with JSON, when requested with `Accept: application/json` or `?format=json`, otherwise with a plain text table
+//
+// Example:
+//
+//	r.Get("/_debug/routes", r.RoutesHandler())
+func (r *Router) RoutesHandler() Handler {
+	return func(c *Context) error {
+		routes := r.root().Routes()
+
+		if c.QueryParam("format") == "json" || strings.Contains(c.GetHeaders().Get("Accept"), "application/json") {
+			return c.SendJSON(routes)
+		}
+
+		c.SetHeader("Content-Type", "text/plain; charset=utf-8")
+
+		tw := tabwriter.NewWriter(c, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "METHOD\tPATTERN\tNAME\tTAGS\tMIDDLEWARES\tHANDLERS")
+		for _, route := range routes {
+			method := route.Method
+			if method == "" {
+				method = "*"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n", method, route.Pattern, route.Name, strings.Join(route.Tags, ","), route.Middlewares, strings.Join(route.Handlers, ", "))
+		}
+		return tw.Flush()
+	}
+}
+
+func handlerNames(handlers ...Handler) []string {
+	names := make([]string, 0, len(handlers))
+	for _, h := range handlers {
+		names = append(names, handlerName(h))
+	}
+	return names
+}
+
+// handlerName returns function name for func handlers, and type name for every other http.Handler
+func handlerName(h any) string {
+	v := reflect.ValueOf(h)
+	if v.Kind() != reflect.Func {
+		return fmt.Sprintf("%T", h)
+	}
+
+	if fn := runtime.FuncForPC(v.Pointer()); fn != nil {
+		return fn.Name()
+	}
+
+	return fmt.Sprintf("%T", h)
+}
@@ -0,0 +1,312 @@
+package ivy
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a [JSON Schema](https://json-schema.org/draft/2020-12/json-schema-core), as used by OpenAPI 3.1 documents
+type Schema struct {
+	Ref         string      `json:"$ref,omitempty"`
+	Type        SchemaTypes `json:"type,omitempty"`
+	Format      string      `json:"format,omitempty"`
+	Title       string      `json:"title,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Enum        []any       `json:"enum,omitempty"`
+	Default     any         `json:"default,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+
+	Items    *Schema `json:"items,omitempty"`
+	MinItems *int    `json:"minItems,omitempty"`
+	MaxItems *int    `json:"maxItems,omitempty"`
+
+	MinLength       *int   `json:"minLength,omitempty"`
+	MaxLength       *int   `json:"maxLength,omitempty"`
+	Pattern         string `json:"pattern,omitempty"`
+	ContentEncoding string `json:"contentEncoding,omitempty"`
+
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+
+	AllOf []*Schema `json:"allOf,omitempty"`
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+	OneOf []*Schema `json:"oneOf,omitempty"`
+	Not   *Schema   `json:"not,omitempty"`
+}
+
+// SchemaTypes are the types of a schema, it is encoded as a string when there is just one type, and as an array otherwise
+type SchemaTypes []string
+
+// MarshalJSON implements json.Marshaler.
+func (st SchemaTypes) MarshalJSON() ([]byte, error) {
+	if len(st) == 1 {
+		return json.Marshal(st[0])
+	}
+	return json.Marshal([]string(st))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (st *SchemaTypes) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*st = SchemaTypes{s}
+		return nil
+	}
+	return json.Unmarshal(b, (*[]string)(st))
+}
+
+// schemaGenerator reflects go types into schemas, named struct types are added to schemas, and referred to with $ref
+type schemaGenerator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	durationType      = reflect.TypeFor[time.Duration]()
+	uuidType          = reflect.TypeFor[UUID]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	jsonNumberType    = reflect.TypeFor[json.Number]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+)
+
+func (sg *schemaGenerator) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: SchemaTypes{"string"}, Format: "date-time"}
+	case durationType:
+		return &Schema{Type: SchemaTypes{"integer"}, Format: "int64"}
+	case uuidType:
+		return &Schema{Type: SchemaTypes{"string"}, Format: "uuid"}
+	case rawMessageType:
+		return &Schema{}
+	case jsonNumberType:
+		return &Schema{Type: SchemaTypes{"number"}}
+	}
+
+	// INFO: types with custom json encoding can be anything, but text marshalers are always encoded as strings
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return &Schema{}
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return &Schema{Type: SchemaTypes{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: SchemaTypes{"boolean"}}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: SchemaTypes{"integer"}, Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: SchemaTypes{"integer"}, Format: "int32"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: SchemaTypes{"integer"}, Minimum: Ptr(0.0)}
+	case reflect.Float32:
+		return &Schema{Type: SchemaTypes{"number"}, Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: SchemaTypes{"number"}, Format: "double"}
+	case reflect.String:
+		return &Schema{Type: SchemaTypes{"string"}}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &Schema{Type: SchemaTypes{"string"}, ContentEncoding: "base64"}
+		}
+		s := &Schema{Type: SchemaTypes{"array"}, Items: sg.schema(t.Elem())}
+		if t.Kind() == reflect.Array {
+			s.MinItems, s.MaxItems = Ptr(t.Len()), Ptr(t.Len())
+		}
+		return s
+	case reflect.Map:
+		return &Schema{Type: SchemaTypes{"object"}, AdditionalProperties: sg.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return sg.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + sg.componentName(t)}
+	}
+
+	// interfaces, and anything else that json can not encode
+	return &Schema{}
+}
+
+var invalidComponentChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// componentName registers schema for a named struct type, and returns its name in components
+func (sg *schemaGenerator) componentName(t reflect.Type) string {
+	if name, ok := sg.names[t]; ok {
+		return name
+	}
+
+	name := strings.Trim(invalidComponentChars.ReplaceAllString(t.Name(), "_"), "_")
+	if _, ok := sg.schemas[name]; ok {
+		// INFO: same type name from another package
+		pkg := t.PkgPath()[strings.LastIndexByte(t.PkgPath(), '/')+1:]
+		name = invalidComponentChars.ReplaceAllString(pkg, "_") + "." + name
+		for i := 2; sg.schemas[name] != nil; i++ {
+			name = fmt.Sprintf("%s.%s%d", pkg, t.Name(), i)
+		}
+	}
+
+	// INFO: registering name first, so that recursive types refer to themselves
+	sg.names[t] = name
+	sg.schemas[name] = &Schema{}
+	*sg.schemas[name] = *sg.structSchema(t)
+	return name
+}
+
+func (sg *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: SchemaTypes{"object"}}
+	for i := 0; i < t.NumField(); i++ {
+		sg.addProperty(s, t.Field(i))
+	}
+	return s
+}
+
+// addProperty adds struct field to object schema s, as per its `json` and `validate` tags. Embedded structs are flattened
+func (sg *schemaGenerator) addProperty(s *Schema, sf reflect.StructField) {
+	name, opts, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "-" && opts == "" {
+		return
+	}
+
+	ft := sf.Type
+	for ft.Kind() == reflect.Pointer {
+		ft = ft.Elem()
+	}
+
+	if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+		for i := 0; i < ft.NumField(); i++ {
+			sg.addProperty(s, ft.Field(i))
+		}
+		return
+	}
+
+	if !sf.IsExported() {
+		return
+	}
+
+	if name == "" {
+		name = sf.Name
+	}
+
+	rules := sf.Tag.Get("validate")
+
+	schema := sg.fieldSchema(sf.Type, rules)
+	if strings.Contains(","+opts+",", ",string,") && schema.Ref == "" {
+		schema.Type = SchemaTypes{"string"}
+		schema.Format = ""
+	}
+
+	if s.Properties == nil {
+		s.Properties = make(map[string]*Schema)
+	}
+	s.Properties[name] = schema
+
+	if hasRule(rules, "required") {
+		s.Required = append(s.Required, name)
+	}
+}
+
+// fieldSchema is schema of type t, with constraints from `validate` rules, as supported by [Validate]
+func (sg *schemaGenerator) fieldSchema(t reflect.Type, rules string) *Schema {
+	s := sg.schema(t)
+	if rules == "" || s.Ref != "" {
+		return s
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "min", "max", "len":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+
+			switch t.Kind() {
+			case reflect.String:
+				if name != "max" {
+					s.MinLength = Ptr(int(n))
+				}
+				if name != "min" {
+					s.MaxLength = Ptr(int(n))
+				}
+			case reflect.Slice, reflect.Array:
+				if name != "max" {
+					s.MinItems = Ptr(int(n))
+				}
+				if name != "min" {
+					s.MaxItems = Ptr(int(n))
+				}
+			case reflect.Map:
+			default:
+				if name == "min" {
+					s.Minimum = Ptr(n)
+				}
+				if name == "max" {
+					s.Maximum = Ptr(n)
+				}
+			}
+		case "email":
+			s.Format = "email"
+		case "url":
+			s.Format = "uri"
+		case "uuid":
+			s.Format = "uuid"
+		case "oneof":
+			for _, option := range strings.Fields(param) {
+				s.Enum = append(s.Enum, enumValue(t, option))
+			}
+		}
+	}
+
+	return s
+}
+
+// enumValue converts oneof option (or default value) into the json value of kind of t
+func enumValue(t reflect.Type, option string) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(option, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseUint(option, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(option, 64); err == nil {
+			return n
+		}
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(option); err == nil {
+			return b
+		}
+	}
+	return option
+}
+
+func ptrTo[T any](v T) *T {
+	return &v
+}
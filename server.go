@@ -0,0 +1,219 @@
+package ivy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ServerOptions configures a [Server]
+type ServerOptions struct {
+	// Addr to listen on, either a TCP address like ":8080", or a unix socket like "unix:/run/app.sock".
+	// It defaults to ":http"
+	Addr string
+
+	// ReadHeaderTimeout defaults to 10 seconds, and IdleTimeout to 2 minutes. Negative values disable them.
+	ReadHeaderTimeout time.Duration
+	IdleTimeout       time.Duration
+
+	// ReadTimeout and WriteTimeout are not set by default, as they would cut streaming responses (like SSE and WebSockets) short
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// DrainDelay is the time, server keeps accepting requests after it is asked to shut down, while its readiness reports 503.
+	// It allows load balancers to notice the readiness change, before connections start getting refused
+	DrainDelay time.Duration
+
+	// DrainTimeout is the time, in-flight requests (and OnShutdown hooks) get to finish, defaults to 30 seconds
+	DrainTimeout time.Duration
+
+	// Signals that start a graceful shutdown, default to SIGINT and SIGTERM
+	Signals []os.Signal
+}
+
+const (
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultIdleTimeout       = 2 * time.Minute
+	DefaultDrainTimeout      = 30 * time.Second
+)
+
+func (o *ServerOptions) withDefaultsIfMissing() {
+	if o.Addr == "" {
+		o.Addr = ":http"
+	}
+	if o.ReadHeaderTimeout == 0 {
+		o.ReadHeaderTimeout = DefaultReadHeaderTimeout
+	}
+	if o.IdleTimeout == 0 {
+		o.IdleTimeout = DefaultIdleTimeout
+	}
+	if o.DrainTimeout <= 0 {
+		o.DrainTimeout = DefaultDrainTimeout
+	}
+	if o.Signals == nil {
+		o.Signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+}
+
+// Server runs a [Router] with graceful shutdown, i.e. on SIGINT/SIGTERM (or when its context is cancelled),
+// it reports not ready, stops accepting new connections, and waits for in-flight requests to finish.
+//
+// Example:
+//
+//	srv := ivy.NewServer(r, ivy.ServerOptions{Addr: ":8089", DrainTimeout: 10 * time.Second})
+//	r.Get("/_ready", srv.ReadinessHandler())
+//	srv.OnShutdown(func(ctx context.Context) error {
+//	    return db.Close()
+//	})
+//
+//	if err := srv.ListenAndServe(context.Background()); err != nil {
+//	    log.Fatal(err)
+//	}
+type Server struct {
+	opts   ServerOptions
+	server *http.Server
+	ready  atomic.Bool
+
+	onStart    []func(ctx context.Context) error
+	onShutdown []func(ctx context.Context) error
+}
+
+// NewServer creates a server for router r
+func NewServer(r *Router, opts ...ServerOptions) *Server {
+	var opt ServerOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	opt.withDefaultsIfMissing()
+
+	return &Server{
+		opts: opt,
+		server: &http.Server{
+			Handler:           r,
+			ReadHeaderTimeout: opt.ReadHeaderTimeout,
+			ReadTimeout:       opt.ReadTimeout,
+			WriteTimeout:      opt.WriteTimeout,
+			IdleTimeout:       opt.IdleTimeout,
+		},
+	}
+}
+
+// OnStart registers fn to run once server is listening, before it starts serving requests.
+// Hooks run in the order they are registered, and an error from any of them stops the server from starting
+func (s *Server) OnStart(fn func(ctx context.Context) error) {
+	s.onStart = append(s.onStart, fn)
+}
+
+// OnShutdown registers fn to run once in-flight requests have been drained.
+// Hooks run in reverse order of their registration, and their ctx expires with DrainTimeout
+func (s *Server) OnShutdown(fn func(ctx context.Context) error) {
+	s.onShutdown = append(s.onShutdown, fn)
+}
+
+// Ready reports whether server is serving requests, it is false before start, and while draining
+func (s *Server) Ready() bool {
+	return s.ready.Load()
+}
+
+// ReadinessHandler responds with 200, while server is ready, and with 503 otherwise (see [Server.Ready])
+func (s *Server) ReadinessHandler() Handler {
+	return func(c *Context) error {
+		if !s.Ready() {
+			return c.Status(http.StatusServiceUnavailable).SendString("not ready")
+		}
+		return c.SendString("ok")
+	}
+}
+
+// ListenAndServe listens on ServerOptions.Addr, and serves requests until ctx is cancelled, or a shutdown signal is received.
+// It returns nil, when the server has been shut down gracefully
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	ln, err := listen(s.opts.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+func listen(addr string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, "unix:")
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+
+	// INFO: socket file of a previous run, that did not exit cleanly, would fail the listen with "address already in use"
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+// Serve is like [Server.ListenAndServe], but serves requests from ln.
+//
+// After the first shutdown signal, signals are no longer handled, i.e. sending another one kills the process without waiting for the drain.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	ctx, stop := signal.NotifyContext(ctx, s.opts.Signals...)
+	defer stop()
+
+	for _, fn := range s.onStart {
+		if err := fn(ctx); err != nil {
+			ln.Close()
+			return err
+		}
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.server.Serve(ln)
+	}()
+
+	s.ready.Store(true)
+	Logger.Info("server started", "addr", ln.Addr().String())
+
+	select {
+	case err := <-serveErr:
+		s.ready.Store(false)
+		return err
+	case <-ctx.Done():
+	}
+
+	stop()
+	s.ready.Store(false)
+	Logger.Info("server shutting down", "drain_delay", s.opts.DrainDelay, "drain_timeout", s.opts.DrainTimeout)
+
+	if s.opts.DrainDelay > 0 {
+		time.Sleep(s.opts.DrainDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.opts.DrainTimeout)
+	defer cancel()
+
+	var errs []error
+	if err := s.server.Shutdown(shutdownCtx); err != nil {
+		// INFO: requests, that are still running, are cut off
+		s.server.Close()
+		errs = append(errs, fmt.Errorf("draining requests: %w", err))
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		errs = append(errs, err)
+	}
+
+	for i := len(s.onShutdown) - 1; i >= 0; i-- {
+		if err := s.onShutdown[i](shutdownCtx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	Logger.Info("server stopped")
+	return errors.Join(errs...)
+}
@@ -0,0 +1,193 @@
+package ivy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is a server-sent event, see [SSEStream.Send]
+type Event struct {
+	// ID is sent back by clients as `Last-Event-ID` header, when they reconnect
+	ID string
+
+	// Event is the event type, clients listen for it with `addEventListener`, defaults to "message" on the client
+	Event string
+
+	// Data is sent as one `data:` line per line of it
+	Data string
+
+	// Retry asks clients to wait for this long, before reconnecting
+	Retry time.Duration
+}
+
+// SSEOptions configures a server-sent events stream
+type SSEOptions struct {
+	// Heartbeat is the interval, at which comments are sent to keep the connection alive through proxies.
+	// It defaults to 15 seconds, and a negative interval disables heartbeats
+	Heartbeat time.Duration
+}
+
+// DefaultSSEHeartbeat is the heartbeat interval of SSE streams, when it is not set in SSEOptions
+const DefaultSSEHeartbeat = 15 * time.Second
+
+// SSEStream is a stream of [server-sent events](https://html.spec.whatwg.org/multipage/server-sent-events.html), created with [Context.SSE]
+type SSEStream struct {
+	c *Context
+
+	mu     sync.Mutex
+	closed bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// SSE starts a server-sent events stream, by writing `text/event-stream` headers.
+// Stream is closed when the client disconnects (i.e. c is cancelled), or when the handler returns.
+//
+// Example:
+//
+//	r.Get("/progress", func(c *ivy.Context) error {
+//	    stream := c.SSE()
+//	    for p := range progress(c, stream.LastEventID()) {
+//	        if err := stream.Send(ivy.Event{ID: p.ID, Event: "progress", Data: p.Message}); err != nil {
+//	            return err
+//	        }
+//	    }
+//	    return nil
+//	})
+func (c *Context) SSE(opts ...SSEOptions) *SSEStream {
+	var opt SSEOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Heartbeat == 0 {
+		opt.Heartbeat = DefaultSSEHeartbeat
+	}
+
+	h := c.response.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	// INFO: disables response buffering of nginx
+	h.Set("X-Accel-Buffering", "no")
+	h.Del("Content-Length")
+
+	c.response.WriteHeader(http.StatusOK)
+	c.Flush()
+
+	s := &SSEStream{c: c, stop: make(chan struct{})}
+
+	if opt.Heartbeat > 0 {
+		s.wg.Add(1)
+		go s.heartbeat(opt.Heartbeat)
+	}
+
+	// INFO: heartbeats must stop before the handler returns, as writing to a finished response is not allowed
+	c.onDone(s.Close)
+
+	return s
+}
+
+func (s *SSEStream) heartbeat(interval time.Duration) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-s.c.Done():
+			return
+		case <-ticker.C:
+			if err := s.Comment("heartbeat"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// LastEventID returns the `Last-Event-ID` header, that clients send when reconnecting, so that the stream can be resumed after it
+func (s *SSEStream) LastEventID() string {
+	return s.c.request.Header.Get("Last-Event-ID")
+}
+
+// Send writes event e, and flushes it to the client.
+// Once the client has disconnected or the stream is closed, it returns an error, without writing anything
+func (s *SSEStream) Send(e Event) error {
+	var sb strings.Builder
+
+	if e.ID != "" {
+		sb.WriteString("id: " + singleLine(e.ID) + "\n")
+	}
+	if e.Event != "" {
+		sb.WriteString("event: " + singleLine(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(&sb, "retry: %d\n", e.Retry.Milliseconds())
+	}
+	if e.Data != "" {
+		data := strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(e.Data)
+		for _, line := range strings.Split(data, "\n") {
+			sb.WriteString("data: " + line + "\n")
+		}
+	}
+	sb.WriteString("\n")
+
+	return s.write(sb.String())
+}
+
+// Comment writes a comment line, which clients ignore
+func (s *SSEStream) Comment(text string) error {
+	return s.write(": " + singleLine(text) + "\n\n")
+}
+
+func (s *SSEStream) write(msg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return io.ErrClosedPipe
+	}
+	if err := s.c.Err(); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(s.c.response, msg); err != nil {
+		return err
+	}
+	// INFO: writers, that can not flush, still get the events, just not as soon as they are sent
+	if err := http.NewResponseController(s.c.response).Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+// Done is closed, when the client disconnects
+func (s *SSEStream) Done() <-chan struct{} {
+	return s.c.Done()
+}
+
+// Close stops heartbeats, and any further writes to the stream. It is called automatically, when the handler returns
+func (s *SSEStream) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.stop)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// singleLine drops line breaks, which are not allowed in id, event and comment fields
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
@@ -0,0 +1,194 @@
+package ivy_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+type Pagination struct {
+	Page    int `query:"page" default:"1"`
+	PerPage int `query:"per_page" default:"10"`
+}
+
+type ListRequest struct {
+	Pagination
+
+	OrgID   int64         `path:"org"`
+	Tags    []string      `query:"tag"`
+	Tenant  string        `header:"X-Tenant"`
+	Session *string       `cookie:"sid"`
+	Since   *time.Time    `query:"since"`
+	Wait    time.Duration `query:"wait" default:"1s"`
+	Flags   []int         `query:"flag" default:"1,2"`
+	ignored string        `query:"ignored"`
+}
+
+func TestBind(t *testing.T) {
+	var got ListRequest
+
+	r := ivy.NewRouter()
+	r.Get("/orgs/{org}/items", func(c *ivy.Context) error {
+		got = ListRequest{}
+		return c.Bind(&got)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orgs/7/items?page=3&tag=a&tag=b&since=2024-01-02T15:04:05Z&ignored=x", nil)
+	req.Header.Set("X-Tenant", "acme")
+	req.AddCookie(&http.Cookie{Name: "sid", Value: "s3cr3t"})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status code: got %d, want %d (body: %q)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	if got.Page != 3 || got.PerPage != 10 {
+		t.Errorf("pagination: got %+v", got.Pagination)
+	}
+
+	if got.OrgID != 7 {
+		t.Errorf("path param: got %d, want 7", got.OrgID)
+	}
+
+	if strings.Join(got.Tags, ",") != "a,b" {
+		t.Errorf("query slice: got %v", got.Tags)
+	}
+
+	if got.Tenant != "acme" {
+		t.Errorf("header: got %q", got.Tenant)
+	}
+
+	if got.Session == nil || *got.Session != "s3cr3t" {
+		t.Errorf("cookie: got %v", got.Session)
+	}
+
+	if got.Since == nil || !got.Since.Equal(time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)) {
+		t.Errorf("time: got %v", got.Since)
+	}
+
+	if got.Wait != time.Second {
+		t.Errorf("duration default: got %v", got.Wait)
+	}
+
+	if len(got.Flags) != 2 || got.Flags[0] != 1 || got.Flags[1] != 2 {
+		t.Errorf("slice default: got %v", got.Flags)
+	}
+
+	if got.ignored != "" {
+		t.Errorf("unexported field must not be bound")
+	}
+}
+
+func TestBindForm(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Post("/", func(c *ivy.Context) error {
+		var in struct {
+			Name  string   `form:"name"`
+			Langs []string `form:"lang"`
+		}
+		if err := c.Bind(&in); err != nil {
+			return err
+		}
+		return c.SendString(in.Name + ":" + strings.Join(in.Langs, ","))
+	})
+
+	form := url.Values{"name": {"ivy"}, "lang": {"go", "nix"}}
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() != "ivy:go,nix" {
+		t.Errorf("body: got %q, want %q", w.Body.String(), "ivy:go,nix")
+	}
+}
+
+func TestBindForm_NotFromQuery(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Post("/", func(c *ivy.Context) error {
+		var in struct {
+			Name string `form:"name"`
+			Role string `form:"role" default:"member"`
+		}
+		if err := c.Bind(&in); err != nil {
+			return err
+		}
+		return c.SendString(in.Name + ":" + in.Role)
+	})
+
+	var multipartBody bytes.Buffer
+	mw := multipart.NewWriter(&multipartBody)
+	mw.WriteField("name", "ivy")
+	mw.Close()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{name: "1. url-encoded", contentType: "application/x-www-form-urlencoded", body: "name=ivy"},
+		{name: "2. multipart", contentType: mw.FormDataContentType(), body: multipartBody.String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// INFO: query values must not override, or fill in for values of the form body
+			req := httptest.NewRequest(http.MethodPost, "/?name=mallory&role=admin", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Body.String() != "ivy:member" {
+				t.Errorf("body: got %q, want %q", w.Body.String(), "ivy:member")
+			}
+		})
+	}
+}
+
+func TestBindAggregatesErrors(t *testing.T) {
+	r := ivy.NewRouter()
+	r.ErrorHandler = func(c *ivy.Context, err error) {
+		c.Status(err.(ivy.HTTPError).Code()).SendJSON(ivy.ErrorFormatJSON(err))
+	}
+	r.Get("/", func(c *ivy.Context) error {
+		var in struct {
+			Page  int           `query:"page"`
+			Wait  time.Duration `query:"wait"`
+			Admin bool          `header:"X-Admin"`
+		}
+		return c.Bind(&in)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/?page=abc&wait=forever", nil)
+	req.Header.Set("X-Admin", "maybe")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status code: got %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	var body struct {
+		Errors []string `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(body.Errors) != 3 {
+		t.Fatalf("errors: got %v, want 3 errors", body.Errors)
+	}
+
+	if body.Errors[0] != `query "page": "abc" is invalid syntax` {
+		t.Errorf("errors[0]: got %q", body.Errors[0])
+	}
+}
@@ -0,0 +1,76 @@
+package ivy_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+
+	parse := func(c *ivy.Context) error {
+		var p payload
+		if err := c.ParseBodyInto(&p); err != nil {
+			return err
+		}
+		return c.SendString(p.Name)
+	}
+
+	r := ivy.NewRouter()
+	r.MaxBodySize = 32
+	r.Post("/small", parse)
+	r.Post("/large", parse).MaxBodySize(1 << 10)
+	r.Post("/unlimited", parse).MaxBodySize(-1)
+	r.Post("/raw", func(c *ivy.Context) error {
+		_, err := io.ReadAll(c.Body())
+		return err
+	})
+
+	g := r.Route("/group")
+	g.Post("/inherited", parse)
+
+	sub := ivy.NewRouter()
+	sub.MaxBodySize = 16
+	sub.Post("/own", parse)
+	r.Mount("/sub", sub)
+
+	uploads := ivy.NewRouter()
+	uploads.Post("/large", parse).MaxBodySize(1 << 10)
+	uploads.Post("/inherited", parse)
+	r.Mount("/uploads", uploads)
+
+	long := `{"name": "` + strings.Repeat("a", 100) + `"}`
+
+	tests := []struct {
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{path: "/small", body: `{"name": "ivy"}`, wantStatus: http.StatusOK},
+		{path: "/small", body: long, wantStatus: http.StatusRequestEntityTooLarge},
+		{path: "/large", body: long, wantStatus: http.StatusOK},
+		{path: "/unlimited", body: long, wantStatus: http.StatusOK},
+		{path: "/raw", body: long, wantStatus: http.StatusRequestEntityTooLarge},
+		{path: "/group/inherited", body: long, wantStatus: http.StatusRequestEntityTooLarge},
+		{path: "/sub/own", body: `{"name": "ivy-router"}`, wantStatus: http.StatusRequestEntityTooLarge},
+		{path: "/uploads/large", body: long, wantStatus: http.StatusOK},
+		{path: "/uploads/inherited", body: long, wantStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+			if w.Code != tt.wantStatus {
+				t.Errorf("got %d (%s), want %d", w.Code, strings.TrimSpace(w.Body.String()), tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package ivy_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nxtcoder17/ivy"
+	"github.com/nxtcoder17/ivy/codec/msgpack"
+)
+
+type Item struct {
+	Name  string `json:"name" xml:"name" form:"name"`
+	Count int    `json:"count" xml:"count" form:"count"`
+}
+
+func TestContentNegotiation(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Get("/item", func(c *ivy.Context) error {
+		return c.Send(Item{Name: "ivy", Count: 2})
+	})
+
+	tests := []struct {
+		name            string
+		accept          string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "1. [Send] no accept header defaults to json",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        `{"name":"ivy","count":2}`,
+		},
+		{
+			name:            "2. [Send] xml",
+			accept:          "application/xml",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/xml",
+			wantBody:        `<Item><name>ivy</name><count>2</count></Item>`,
+		},
+		{
+			name:            "3. [Send] q-values pick the preferred codec",
+			accept:          "application/json;q=0.5, application/x-www-form-urlencoded;q=0.9, text/html",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/x-www-form-urlencoded",
+			wantBody:        `count=2&name=ivy`,
+		},
+		{
+			name:            "4. [Send] wildcard",
+			accept:          "text/html, */*;q=0.1",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        `{"name":"ivy","count":2}`,
+		},
+		{
+			name:            "5. [Send] ndjson",
+			accept:          "application/x-ndjson",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/x-ndjson",
+			wantBody:        "{\"name\":\"ivy\",\"count\":2}\n",
+		},
+		{
+			name:       "6. [Send] not acceptable",
+			accept:     "text/html, application/json;q=0",
+			wantStatus: http.StatusNotAcceptable,
+		},
+		{
+			name:            "7. [Send] q=0 excludes a media type from wildcards",
+			accept:          "application/json;q=0, */*",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/xml",
+			wantBody:        `<Item><name>ivy</name><count>2</count></Item>`,
+		},
+		{
+			name:            "8. [Send] most specific range decides the q-value",
+			accept:          "application/*;q=0.2, application/x-ndjson;q=0.8, */*;q=0.5",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/x-ndjson",
+			wantBody:        "{\"name\":\"ivy\",\"count\":2}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/item", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status code: got %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			if got := w.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("content type: got %q, want %q", got, tt.wantContentType)
+			}
+
+			if w.Body.String() != tt.wantBody {
+				t.Errorf("body: got %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestParseBodyIntoCodecs(t *testing.T) {
+	msgpackBody, err := msgpack.Marshal(Item{Name: "packed", Count: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := ivy.NewRouter()
+	r.Codecs = ivy.NewCodecs()
+	r.Codecs.Register(msgpack.MediaType, msgpack.Codec{})
+
+	r.Post("/item", func(c *ivy.Context) error {
+		var item Item
+		if err := c.ParseBodyInto(&item); err != nil {
+			return err
+		}
+		return c.SendJSON(item)
+	})
+
+	r.Post("/items", func(c *ivy.Context) error {
+		var items []Item
+		if err := c.ParseBodyInto(&items); err != nil {
+			return err
+		}
+		return c.SendJSON(items)
+	})
+
+	tests := []struct {
+		name        string
+		route       string
+		contentType string
+		body        []byte
+		wantStatus  int
+		wantBody    string
+	}{
+		{name: "1. [ParseBodyInto] json", route: "/item", contentType: "application/json; charset=utf-8", body: []byte(`{"name":"ivy","count":1}`), wantStatus: http.StatusOK, wantBody: `{"name":"ivy","count":1}`},
+		{name: "2. [ParseBodyInto] xml", route: "/item", contentType: "application/xml", body: []byte(`<Item><name>ivy</name><count>1</count></Item>`), wantStatus: http.StatusOK, wantBody: `{"name":"ivy","count":1}`},
+		{name: "3. [ParseBodyInto] form", route: "/item", contentType: "application/x-www-form-urlencoded", body: []byte(`name=ivy&count=1`), wantStatus: http.StatusOK, wantBody: `{"name":"ivy","count":1}`},
+		{name: "4. [ParseBodyInto] ndjson", route: "/items", contentType: "application/x-ndjson", body: []byte("{\"name\":\"a\"}\n{\"name\":\"b\"}\n"), wantStatus: http.StatusOK, wantBody: `[{"name":"a","count":0},{"name":"b","count":0}]`},
+		{name: "5. [ParseBodyInto] msgpack sub package", route: "/item", contentType: "application/msgpack", body: msgpackBody, wantStatus: http.StatusOK, wantBody: `{"name":"packed","count":3}`},
+		{name: "6. [ParseBodyInto] unsupported media type", route: "/item", contentType: "text/csv", body: []byte("a,b"), wantStatus: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.route, bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status code: got %d, want %d (body: %q)", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body: got %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCodecsPerRouter(t *testing.T) {
+	r := ivy.NewRouter()
+
+	xmlOnly := ivy.NewCodecs()
+	xmlOnly.Remove(ivy.MediaTypeJSON)
+
+	r2 := ivy.NewRouter()
+	r2.Codecs = xmlOnly
+	r2.Get("/item", func(c *ivy.Context) error {
+		return c.Send(Item{Name: "ivy"})
+	})
+	r.Mount("/v2", r2)
+
+	r.Get("/item", func(c *ivy.Context) error {
+		return c.Send(Item{Name: "ivy"})
+	})
+
+	for route, want := range map[string]string{"/item": "application/json", "/v2/item": "application/xml"} {
+		req := httptest.NewRequest(http.MethodGet, route, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Content-Type"); got != want {
+			t.Errorf("%s content type: got %q, want %q", route, got, want)
+		}
+	}
+}
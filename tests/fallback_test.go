@@ -0,0 +1,214 @@
+package ivy_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func TestFallbackHandlers(t *testing.T) {
+	newRouter := func() (*ivy.Router, *int) {
+		var middlewareCalls int
+
+		r := ivy.NewRouter()
+		r.ErrorHandler = func(c *ivy.Context, err error) {
+			code := http.StatusInternalServerError
+			if he, ok := err.(ivy.HTTPError); ok {
+				code = he.Code()
+			}
+			c.Status(code).SendJSON(map[string]string{"error": err.Error()})
+		}
+		r.Use(func(c *ivy.Context) error {
+			middlewareCalls++
+			return c.Next()
+		})
+		r.Get("/users", func(c *ivy.Context) error { return c.SendString("users") })
+		r.Post("/users", func(c *ivy.Context) error { return c.SendString("created") })
+		return r, &middlewareCalls
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		route      string
+		setup      func(r *ivy.Router)
+		wantStatus int
+		wantBody   string
+		wantAllow  string
+	}{
+		{
+			name:       "1. [NotFound] goes through error handler",
+			method:     http.MethodGet,
+			route:      "/unknown",
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":"404 page not found"}`,
+		},
+		{
+			name:       "2. [MethodNotAllowed] goes through error handler, with Allow header",
+			method:     http.MethodDelete,
+			route:      "/users",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   `{"error":"Method Not Allowed"}`,
+			wantAllow:  "GET, HEAD, POST, OPTIONS",
+		},
+		{
+			name:       "3. [OPTIONS] automatic response",
+			method:     http.MethodOptions,
+			route:      "/users",
+			wantStatus: http.StatusNoContent,
+			wantAllow:  "GET, HEAD, POST, OPTIONS",
+		},
+		{
+			name:   "4. [NotFound] custom handler",
+			method: http.MethodGet,
+			route:  "/unknown",
+			setup: func(r *ivy.Router) {
+				r.NotFound(func(c *ivy.Context) error {
+					return c.Status(http.StatusNotFound).SendString("nothing here")
+				})
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   "nothing here",
+		},
+		{
+			name:   "5. [MethodNotAllowed] custom handler",
+			method: http.MethodPut,
+			route:  "/users",
+			setup: func(r *ivy.Router) {
+				r.MethodNotAllowed(func(c *ivy.Context) error {
+					return c.Status(http.StatusMethodNotAllowed).SendString("use " + c.ResponseWriter().Header().Get("Allow"))
+				})
+			},
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   "use GET, HEAD, POST, OPTIONS",
+			wantAllow:  "GET, HEAD, POST, OPTIONS",
+		},
+		{
+			name:       "6. [MethodNotAllowed] path with wildcards",
+			method:     http.MethodPatch,
+			route:      "/users/42/files/a/b.txt",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   `{"error":"Method Not Allowed"}`,
+			wantAllow:  "GET, HEAD, PUT, OPTIONS",
+			setup: func(r *ivy.Router) {
+				r.Get("/users/{id}/files/{path...}", func(c *ivy.Context) error { return nil })
+				r.Put("/users/{id}/files/{path...}", func(c *ivy.Context) error { return nil })
+				r.Delete("/users/{id}/{$}", func(c *ivy.Context) error { return nil })
+			},
+		},
+		{
+			name:       "7. [NotFound] path only partially matching a route",
+			method:     http.MethodGet,
+			route:      "/users/42",
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":"404 page not found"}`,
+			setup: func(r *ivy.Router) {
+				r.Get("/users/{id}/files/{path...}", func(c *ivy.Context) error { return nil })
+			},
+		},
+		{
+			name:   "8. [NotFound] inside mounted router, uses parent's handler",
+			method: http.MethodGet,
+			route:  "/v2/unknown",
+			setup: func(r *ivy.Router) {
+				r.NotFound(func(c *ivy.Context) error {
+					return c.Status(http.StatusNotFound).SendString("parent not found")
+				})
+				r2 := ivy.NewRouter()
+				r2.Get("/items", func(c *ivy.Context) error { return nil })
+				r.Mount("/v2", r2)
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   "parent not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, middlewareCalls := newRouter()
+			if tt.setup != nil {
+				tt.setup(r)
+			}
+
+			req := httptest.NewRequest(tt.method, tt.route, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status code: got %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			if w.Body.String() != tt.wantBody {
+				t.Errorf("body: got %q, want %q", w.Body.String(), tt.wantBody)
+			}
+
+			if got := w.Header().Get("Allow"); got != tt.wantAllow {
+				t.Errorf("Allow header: got %q, want %q", got, tt.wantAllow)
+			}
+
+			if *middlewareCalls == 0 {
+				t.Errorf("expected router middlewares to run for fallback handlers")
+			}
+		})
+	}
+}
+
+func TestFallbackHandlers_CatchAllRoute(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Get("/users", func(c *ivy.Context) error { return c.SendString("users") })
+	r.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("catch-all"))
+	})
+
+	// INFO: a catch-all route answers unmatched requests itself
+	for _, path := range []string{"/unknown", "/a/b/c"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK || w.Body.String() != "catch-all" {
+			t.Errorf("%s: got %d %q", path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestFallbackHandlers_CatchAllRouteAfterServing(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Get("/users", func(c *ivy.Context) error { return c.SendString("users") })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("before catch-all route: got %d", w.Code)
+	}
+
+	// INFO: registering a catch-all route, once the router has served requests, must not conflict with the fallback route
+	r.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("catch-all " + req.Pattern))
+	}))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "catch-all /" {
+		t.Errorf("after catch-all route: got %d %q", w.Code, w.Body.String())
+	}
+
+	sub := ivy.NewRouter()
+	sub.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	sub.HandleFunc("/{path...}", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.PathValue("path")))
+	})
+
+	w = httptest.NewRecorder()
+	sub.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/a/b/c", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "a/b/c" {
+		t.Errorf("wildcard catch-all route: got %d %q", w.Code, w.Body.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a second catch-all route to panic")
+		}
+	}()
+	r.Handle("/{path...}", http.NotFoundHandler())
+}
@@ -0,0 +1,92 @@
+package ivy_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func TestNamedRoutes(t *testing.T) {
+	noop := func(c *ivy.Context) error { return nil }
+
+	r := ivy.NewRouter()
+	r.Get("/users/{id}", noop).Name("user.show")
+	r.Get("/users/{id}/posts/{$}", noop).Name("user.posts")
+	r.Get("/files/{path...}", noop).Name("files")
+
+	r.Group("/admin", func(g *ivy.Router) {
+		g.Get("/stats", noop).Name("admin.stats")
+	})
+
+	r2 := ivy.NewRouter()
+	r2.Get("/items/{id}", noop).Name("v2.item")
+	r.Mount("/v2", r2)
+
+	tests := []struct {
+		name    string
+		route   string
+		params  []any
+		want    string
+		wantErr bool
+	}{
+		{name: "1. [URL] single wildcard", route: "user.show", params: []any{"id", 12}, want: "/users/12"},
+		{name: "2. [URL] wildcard is escaped", route: "user.show", params: []any{"id", "a b/c"}, want: "/users/a%20b%2Fc"},
+		{name: "3. [URL] {$} is dropped", route: "user.posts", params: []any{"id", "1"}, want: "/users/1/posts/"},
+		{name: "4. [URL] multi segment wildcard keeps slashes", route: "files", params: []any{"path", "a/b c/d"}, want: "/files/a/b%20c/d"},
+		{name: "5. [URL] group prefix", route: "admin.stats", want: "/admin/stats"},
+		{name: "6. [URL] mount prefix", route: "v2.item", params: []any{"id", 7}, want: "/v2/items/7"},
+		{name: "7. [URL] missing param", route: "user.show", wantErr: true},
+		{name: "8. [URL] unknown route", route: "unknown", wantErr: true},
+		{name: "9. [URL] odd params", route: "user.show", params: []any{"id"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.URL(tt.route, tt.params...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error: got %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("url: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextURLFor(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Get("/users/{id}", func(c *ivy.Context) error { return nil }).Name("user.show")
+
+	r2 := ivy.NewRouter()
+	r2.Get("/link", func(c *ivy.Context) error {
+		u, err := c.URLFor("user.show", "id", 42)
+		if err != nil {
+			return err
+		}
+		return c.SendString(u)
+	})
+	r.Mount("/v2", r2)
+
+	req := httptest.NewRequest(http.MethodGet, "/v2/link", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() != "/users/42" {
+		t.Errorf("body: got %q, want %q", w.Body.String(), "/users/42")
+	}
+}
+
+func TestDuplicateRouteNamePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected duplicate route name to panic")
+		}
+	}()
+
+	r := ivy.NewRouter()
+	r.Get("/a", func(c *ivy.Context) error { return nil }).Name("dup")
+	r.Get("/b", func(c *ivy.Context) error { return nil }).Name("dup")
+}
@@ -0,0 +1,144 @@
+package ivy_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+type Pet struct {
+	ID      int64    `json:"id"`
+	Name    string   `json:"name" validate:"required,min=1,max=32"`
+	Kind    string   `json:"kind" validate:"oneof=cat dog"`
+	Owner   *Pet     `json:"owner,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	private string
+}
+
+type ListPetsRequest struct {
+	Limit  int    `query:"limit" default:"10" validate:"max=100"`
+	Tenant string `header:"X-Tenant" validate:"required"`
+}
+
+type UpdatePetRequest struct {
+	ID   int64  `path:"id" json:"-"`
+	Name string `json:"name" validate:"required"`
+}
+
+func TestOpenAPI(t *testing.T) {
+	r := ivy.NewRouter()
+
+	ivy.TypedRoute(r.Get, "/pets", func(c *ivy.Context, in ListPetsRequest) ([]Pet, error) {
+		return nil, nil
+	}).Name("pets.list").Summary("list pets").Tags("pets")
+
+	ivy.TypedRoute(r.Put, "/pets/{id:int}", func(c *ivy.Context, in UpdatePetRequest) (Pet, error) {
+		return Pet{}, nil
+	}).Description("updates a pet")
+
+	r.Delete("/pets/{id}", func(c *ivy.Context) error { return nil })
+	r.Handle("/raw", http.NotFoundHandler())
+
+	r2 := ivy.NewRouter()
+	r2.Get("/files/{path...}", func(c *ivy.Context) error { return nil })
+	r.Mount("/v2", r2)
+
+	doc := r.OpenAPI(ivy.OpenAPIInfo{Title: "Pets", Version: "1.0.0"})
+
+	if doc.OpenAPI != ivy.OpenAPIVersion {
+		t.Errorf("openapi version: got %q", doc.OpenAPI)
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	if len(doc.Paths) != 3 || doc.Paths["/pets"] == nil || doc.Paths["/pets/{id}"] == nil || doc.Paths["/v2/files/{path}"] == nil {
+		t.Fatalf("paths: got %v", paths)
+	}
+
+	list := doc.Paths["/pets"]["get"]
+	if list.OperationID != "pets.list" || list.Summary != "list pets" || len(list.Tags) != 1 {
+		t.Errorf("list operation: got %+v", list)
+	}
+
+	if len(list.Parameters) != 2 {
+		t.Fatalf("list parameters: got %d", len(list.Parameters))
+	}
+	if p := list.Parameters[0]; p.Name != "limit" || p.In != "query" || p.Required || p.Schema.Default != int64(10) || *p.Schema.Maximum != 100 {
+		t.Errorf("limit param: got %+v (%+v)", p, p.Schema)
+	}
+	if p := list.Parameters[1]; p.Name != "X-Tenant" || p.In != "header" || !p.Required {
+		t.Errorf("tenant param: got %+v", p)
+	}
+
+	if resp := list.Responses["200"]; resp == nil || resp.Content["application/json"].Schema.Items.Ref != "#/components/schemas/Pet" {
+		t.Errorf("list response: got %+v", list.Responses)
+	}
+
+	pet := doc.Components.Schemas["Pet"]
+	if pet == nil {
+		t.Fatalf("Pet schema is missing")
+	}
+	if len(pet.Properties) != 5 || pet.Properties["owner"].Ref != "#/components/schemas/Pet" {
+		t.Errorf("Pet properties: got %+v", pet.Properties)
+	}
+	if len(pet.Required) != 1 || pet.Required[0] != "name" || *pet.Properties["name"].MaxLength != 32 || len(pet.Properties["kind"].Enum) != 2 {
+		t.Errorf("Pet validation: got %+v", pet)
+	}
+
+	update := doc.Paths["/pets/{id}"]["put"]
+	if update.Description != "updates a pet" || len(update.Parameters) != 1 {
+		t.Fatalf("update operation: got %+v", update)
+	}
+	if p := update.Parameters[0]; p.In != "path" || !p.Required || p.Schema.Type[0] != "integer" || p.Schema.Pattern == "" {
+		t.Errorf("id param: got %+v (%+v)", p, p.Schema)
+	}
+	body := update.RequestBody.Content["application/json"].Schema
+	if len(body.Properties) != 1 || body.Properties["name"] == nil {
+		t.Errorf("update body: got %+v", body)
+	}
+
+	if p := doc.Paths["/pets/{id}"]["delete"].Parameters; len(p) != 1 || p[0].Schema.Type[0] != "string" {
+		t.Errorf("delete parameters: got %+v", p)
+	}
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	r := ivy.NewRouter()
+	r.ServeOpenAPI("/openapi.json", ivy.OpenAPIInfo{Title: "Pets", Version: "1.0.0"})
+	r.Get("/pets", func(c *ivy.Context) error { return nil }).Summary("list pets")
+
+	t.Run("json", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+		var doc ivy.OpenAPIDocument
+		if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+
+		if doc.Info.Title != "Pets" || doc.Paths["/pets"]["get"].Summary != "list pets" {
+			t.Errorf("document: got %s", w.Body.String())
+		}
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json?format=yaml", nil))
+
+		if ct := w.Header().Get("Content-Type"); ct != "application/yaml" {
+			t.Errorf("content type: got %q", ct)
+		}
+
+		for _, want := range []string{"openapi: \"3.1.0\"\n", "  /pets:\n    get:\n", "      summary: list pets\n"} {
+			if !strings.Contains(w.Body.String(), want) {
+				t.Errorf("yaml: %q not found in\n%s", want, w.Body.String())
+			}
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package ivy_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func TestTypedPathParams(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Get("/int/{id}", func(c *ivy.Context) error {
+		id, err := c.PathParamInt("id")
+		if err != nil {
+			return err
+		}
+		return c.SendString(fmt.Sprint(id + 1))
+	})
+	r.Get("/int64/{id}", func(c *ivy.Context) error {
+		id, err := c.PathParamInt64("id")
+		if err != nil {
+			return err
+		}
+		return c.SendString(fmt.Sprint(id))
+	})
+	r.Get("/uuid/{id}", func(c *ivy.Context) error {
+		id, err := c.PathParamUUID("id")
+		if err != nil {
+			return err
+		}
+		return c.SendString(id.String())
+	})
+	r.Get("/time/{t}", func(c *ivy.Context) error {
+		t, err := c.PathParamTime("t", time.DateOnly)
+		if err != nil {
+			return err
+		}
+		return c.SendString(t.Weekday().String())
+	})
+	r.Get("/generic/{v}", func(c *ivy.Context) error {
+		v, err := ivy.PathParamAs[uint8](c, "v")
+		if err != nil {
+			return err
+		}
+		return c.SendString(fmt.Sprint(v))
+	})
+
+	tests := []struct {
+		route      string
+		wantStatus int
+		wantBody   string
+	}{
+		{route: "/int/41", wantStatus: http.StatusOK, wantBody: "42"},
+		{route: "/int/abc", wantStatus: http.StatusBadRequest, wantBody: "invalid path param \"id\": \"abc\" is invalid syntax\n"},
+		{route: "/int64/9000000000", wantStatus: http.StatusOK, wantBody: "9000000000"},
+		{route: "/uuid/6BA7B810-9DAD-11D1-80B4-00C04FD430C8", wantStatus: http.StatusOK, wantBody: "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{route: "/uuid/not-a-uuid", wantStatus: http.StatusBadRequest},
+		{route: "/time/2024-01-01", wantStatus: http.StatusOK, wantBody: "Monday"},
+		{route: "/time/yesterday", wantStatus: http.StatusBadRequest},
+		{route: "/generic/255", wantStatus: http.StatusOK, wantBody: "255"},
+		{route: "/generic/256", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.route, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status code: got %d, want %d (body: %q)", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body: got %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestPathConstraints(t *testing.T) {
+	handlerCalled := false
+
+	r := ivy.NewRouter()
+	r.NotFound(func(c *ivy.Context) error {
+		return c.Status(http.StatusNotFound).SendString("not found")
+	})
+	r.Get("/users/{id:int}", func(c *ivy.Context) error {
+		handlerCalled = true
+		return c.SendString("user " + c.PathParam("id"))
+	}).Name("user.show")
+	r.Get("/posts/{slug:[a-z-]+}", func(c *ivy.Context) error {
+		handlerCalled = true
+		return c.SendString("post " + c.PathParam("slug"))
+	})
+	r.Get("/codes/{code:[0-9]{3}}", func(c *ivy.Context) error {
+		handlerCalled = true
+		return c.SendString("code " + c.PathParam("code"))
+	})
+
+	tests := []struct {
+		route      string
+		wantStatus int
+		wantBody   string
+	}{
+		{route: "/users/12", wantStatus: http.StatusOK, wantBody: "user 12"},
+		{route: "/users/abc", wantStatus: http.StatusNotFound, wantBody: "not found"},
+		{route: "/posts/hello-world", wantStatus: http.StatusOK, wantBody: "post hello-world"},
+		{route: "/posts/Hello", wantStatus: http.StatusNotFound, wantBody: "not found"},
+		{route: "/codes/404", wantStatus: http.StatusOK, wantBody: "code 404"},
+		{route: "/codes/4040", wantStatus: http.StatusNotFound, wantBody: "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			handlerCalled = false
+
+			req := httptest.NewRequest(http.MethodGet, tt.route, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status code: got %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			if w.Body.String() != tt.wantBody {
+				t.Errorf("body: got %q, want %q", w.Body.String(), tt.wantBody)
+			}
+
+			if handlerCalled != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("handler called: got %v", handlerCalled)
+			}
+		})
+	}
+
+	if _, err := r.URL("user.show", "id", "abc"); err == nil {
+		t.Errorf("expected URL to fail for param not satisfying constraint")
+	}
+
+	if u, _ := r.URL("user.show", "id", 1); u != "/users/1" {
+		t.Errorf("url: got %q, want %q", u, "/users/1")
+	}
+}
@@ -0,0 +1,98 @@
+package ivy_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func TestResponseState(t *testing.T) {
+	type state struct {
+		committed bool
+		status    int
+		written   int64
+	}
+
+	var before, after state
+
+	r := ivy.NewRouter()
+	r.Use(func(c *ivy.Context) error {
+		err := c.Next()
+		after = state{c.Committed(), c.StatusCode(), c.BytesWritten()}
+		return err
+	})
+	r.Get("/partial", func(c *ivy.Context) error {
+		before = state{c.Committed(), c.StatusCode(), c.BytesWritten()}
+		c.Status(http.StatusAccepted).SendString("partial")
+		return errors.New("failed after write")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/partial", nil))
+
+	if before != (state{}) {
+		t.Errorf("state before write: got %+v", before)
+	}
+
+	if want := (state{true, http.StatusAccepted, 7}); after != want {
+		t.Errorf("state after write: got %+v, want %+v", after, want)
+	}
+
+	// DefaultErrorHandler must not write to a committed response
+	if w.Code != http.StatusAccepted || w.Body.String() != "partial" {
+		t.Errorf("response: got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestResponseStateMounted(t *testing.T) {
+	var committed bool
+
+	sub := ivy.NewRouter()
+	sub.Get("/ping", func(c *ivy.Context) error {
+		return c.SendString("pong")
+	})
+
+	r := ivy.NewRouter()
+	r.Use(func(c *ivy.Context) error {
+		err := c.Next()
+		committed = c.Committed()
+		return err
+	})
+	r.Mount("/sub", sub)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sub/ping", nil))
+
+	if !committed || w.Body.String() != "pong" {
+		t.Errorf("writes in mounted router must be tracked by parent context, got committed=%v, body %q", committed, w.Body.String())
+	}
+}
+
+func TestAfterResponse(t *testing.T) {
+	var got []string
+
+	r := ivy.NewRouter()
+	r.Use(func(c *ivy.Context) error {
+		c.AfterResponse(func() {
+			got = append(got, "first")
+		})
+		c.AfterResponse(func() {
+			// error response of ErrorHandler is already written
+			got = append(got, http.StatusText(c.StatusCode()))
+		})
+		return c.Next()
+	})
+	r.Get("/", func(c *ivy.Context) error {
+		return ivy.NewHTTPError(http.StatusTeapot, "teapot")
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if want := []string{http.StatusText(http.StatusTeapot), "first"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,146 @@
+package ivy_test
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+	"github.com/nxtcoder17/ivy/middleware"
+)
+
+type readerFromRecorder struct {
+	*httptest.ResponseRecorder
+	readFrom bool
+}
+
+func (w *readerFromRecorder) ReadFrom(r io.Reader) (int64, error) {
+	w.readFrom = true
+	return io.Copy(w.ResponseRecorder, r)
+}
+
+type pushRecorder struct {
+	*httptest.ResponseRecorder
+	pushed string
+}
+
+func (w *pushRecorder) Push(target string, _ *http.PushOptions) error {
+	w.pushed = target
+	return nil
+}
+
+// customWriter replaces the context's writer, like a middleware would do
+type customWriter struct {
+	http.ResponseWriter
+}
+
+func (w *customWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func TestResponseWriter(t *testing.T) {
+	t.Run("ReadFrom of underlying writer is used", func(t *testing.T) {
+		rec := &readerFromRecorder{ResponseRecorder: httptest.NewRecorder()}
+		rw := ivy.NewResponseWriter(rec)
+
+		// INFO: io.Copy prefers WriteTo of strings.Reader, so it is hidden
+		n, err := io.Copy(rw, struct{ io.Reader }{strings.NewReader("hello")})
+		if err != nil || n != 5 || !rec.readFrom {
+			t.Errorf("io.Copy: got (%d, %v), readFrom=%v", n, err, rec.readFrom)
+		}
+		if !rw.Committed() || rw.StatusCode() != http.StatusOK || rw.BytesWritten() != 5 {
+			t.Errorf("state: committed=%v status=%d written=%d", rw.Committed(), rw.StatusCode(), rw.BytesWritten())
+		}
+	})
+
+	t.Run("ReadFrom falls back to Write", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rw := ivy.NewResponseWriter(rec)
+
+		if n, err := io.Copy(rw, strings.NewReader("hello")); err != nil || n != 5 || rec.Body.String() != "hello" {
+			t.Errorf("io.Copy: got (%d, %v), body %q", n, err, rec.Body.String())
+		}
+	})
+
+	t.Run("Push reaches pusher through custom writers", func(t *testing.T) {
+		rec := &pushRecorder{ResponseRecorder: httptest.NewRecorder()}
+		rw := ivy.NewResponseWriter(&customWriter{ivy.NewResponseWriter(rec)})
+
+		if err := rw.Push("/app.css", nil); err != nil || rec.pushed != "/app.css" {
+			t.Errorf("push: got %v, pushed %q", err, rec.pushed)
+		}
+	})
+
+	t.Run("unsupported interfaces fail with ErrNotSupported", func(t *testing.T) {
+		rw := ivy.NewResponseWriter(httptest.NewRecorder())
+
+		if _, _, err := rw.Hijack(); !errors.Is(err, http.ErrNotSupported) {
+			t.Errorf("hijack: got %v", err)
+		}
+		if err := rw.Push("/app.css", nil); !errors.Is(err, http.ErrNotSupported) {
+			t.Errorf("push: got %v", err)
+		}
+	})
+}
+
+func TestResponseWriterHijack(t *testing.T) {
+	var committed bool
+	var deadlineErr error
+
+	r := ivy.NewRouter()
+	r.Use(middleware.Logger())
+	r.Use(func(c *ivy.Context) error {
+		c.SetResponseWriter(ivy.NewResponseWriter(&customWriter{c.ResponseWriter()}))
+		err := c.Next()
+		committed = c.Committed()
+		return err
+	})
+	r.Get("/upgrade", func(c *ivy.Context) error {
+		deadlineErr = http.NewResponseController(c.ResponseWriter()).SetWriteDeadline(time.Now().Add(time.Second))
+
+		conn, brw, err := c.ResponseWriter().(http.Hijacker).Hijack()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		brw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\nhijacked")
+		return brw.Flush()
+	})
+
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	io.WriteString(conn, "GET /upgrade HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(br)
+
+	if resp.StatusCode != http.StatusSwitchingProtocols || string(body) != "hijacked" {
+		t.Errorf("response: got %d %q", resp.StatusCode, body)
+	}
+
+	if deadlineErr != nil {
+		t.Errorf("response controller must reach write deadlines, got %v", deadlineErr)
+	}
+
+	if !committed {
+		t.Errorf("hijacked response must be committed")
+	}
+}
@@ -0,0 +1,50 @@
+package ivy_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func TestRoutePattern(t *testing.T) {
+	var seen string
+
+	r := ivy.NewRouter()
+	r.Use(func(c *ivy.Context) error {
+		err := c.Next()
+		seen = c.RoutePattern()
+		return err
+	})
+	r.Get("/users/{id}", func(c *ivy.Context) error { return nil })
+
+	api := r.Route("/api")
+	api.Get("/items/{id...}", func(c *ivy.Context) error { return nil })
+
+	v2 := ivy.NewRouter()
+	v2.Get("/orders/{id}", func(c *ivy.Context) error { return nil })
+
+	v3 := ivy.NewRouter()
+	v3.Get("/{$}", func(c *ivy.Context) error { return nil })
+	v2.Mount("/v3", v3)
+
+	api.Mount("/v2", v2)
+
+	tests := map[string]string{
+		"/users/1":           "GET /users/{id}",
+		"/api/items/a/b":     "GET /api/items/{id...}",
+		"/api/v2/orders/7":   "GET /api/v2/orders/{id}",
+		"/api/v2/v3/":        "GET /api/v2/v3/{$}",
+		"/api/v2/not-found":  "",
+		"/does-not-exist/at": "",
+	}
+
+	for path, want := range tests {
+		seen = "unset"
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		if seen != want {
+			t.Errorf("%s: got %q, want %q", path, seen, want)
+		}
+	}
+}
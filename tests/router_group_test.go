@@ -0,0 +1,184 @@
+package ivy_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func TestRouteGroups(t *testing.T) {
+	var order []string
+
+	r := ivy.NewRouter()
+	r.Use(func(c *ivy.Context) error {
+		order = append(order, "root")
+		return c.Next()
+	})
+
+	r.Get("/public", func(c *ivy.Context) error {
+		return c.SendString("public")
+	})
+
+	r.Group("/admin", func(g *ivy.Router) {
+		g.Use(func(c *ivy.Context) error {
+			order = append(order, "admin")
+			if c.GetHeaders().Get("Authorization") != "secret" {
+				return ivy.NewHTTPError(http.StatusUnauthorized, "unauthorized")
+			}
+			return c.Next()
+		})
+
+		g.Get("/stats", func(c *ivy.Context) error {
+			return c.SendString(c.URL().Path)
+		})
+
+		g.Group("/users", func(g *ivy.Router) {
+			g.Use(func(c *ivy.Context) error {
+				order = append(order, "users")
+				return c.Next()
+			})
+
+			g.Get("/{id}", func(c *ivy.Context) error {
+				return c.SendString("user " + c.PathParam("id"))
+			})
+		})
+	})
+
+	tests := []struct {
+		name       string
+		route      string
+		auth       string
+		wantStatus int
+		wantBody   string
+		wantOrder  []string
+	}{
+		{
+			name:       "1. [Group] route outside group skips group middlewares",
+			route:      "/public",
+			wantStatus: http.StatusOK,
+			wantBody:   "public",
+			wantOrder:  []string{"root"},
+		},
+		{
+			name:       "2. [Group] group middleware rejects request",
+			route:      "/admin/stats",
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   "unauthorized\n",
+			wantOrder:  []string{"root", "admin"},
+		},
+		{
+			name:       "3. [Group] url path is not rewritten",
+			route:      "/admin/stats",
+			auth:       "secret",
+			wantStatus: http.StatusOK,
+			wantBody:   "/admin/stats",
+			wantOrder:  []string{"root", "admin"},
+		},
+		{
+			name:       "4. [Group] nested group layers middlewares",
+			route:      "/admin/users/1",
+			auth:       "secret",
+			wantStatus: http.StatusOK,
+			wantBody:   "user 1",
+			wantOrder:  []string{"root", "admin", "users"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order = nil
+
+			req := httptest.NewRequest(http.MethodGet, tt.route, nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+
+			res := w.Result()
+			defer res.Body.Close()
+
+			if res.StatusCode != tt.wantStatus {
+				t.Errorf("status code: got %d, want %d", res.StatusCode, tt.wantStatus)
+			}
+
+			data, _ := io.ReadAll(res.Body)
+			if string(data) != tt.wantBody {
+				t.Errorf("body: got %q, want %q", data, tt.wantBody)
+			}
+
+			if len(order) != len(tt.wantOrder) {
+				t.Fatalf("middleware order: got %v, want %v", order, tt.wantOrder)
+			}
+			for i := range order {
+				if order[i] != tt.wantOrder[i] {
+					t.Errorf("middleware order: got %v, want %v", order, tt.wantOrder)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestRouteGroupErrorHandler(t *testing.T) {
+	r := ivy.NewRouter()
+	r.ErrorHandler = func(c *ivy.Context, err error) {
+		c.Status(http.StatusTeapot).SendString("root: " + err.Error())
+	}
+
+	api := r.Route("/api")
+	api.Get("/fail", func(c *ivy.Context) error {
+		return io.EOF
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/fail", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusTeapot {
+		t.Errorf("status code: got %d, want %d", w.Code, http.StatusTeapot)
+	}
+
+	if w.Body.String() != "root: EOF" {
+		t.Errorf("body: got %q, want %q", w.Body.String(), "root: EOF")
+	}
+}
+
+func TestMountedRouterErrorHandler(t *testing.T) {
+	r := ivy.NewRouter()
+	api := r.Route("/api")
+
+	sub := ivy.NewRouter()
+	sub.Get("/fail", func(c *ivy.Context) error { return io.EOF })
+	api.Mount("/v1", sub)
+
+	if sub.ErrorHandler != nil {
+		t.Fatalf("mounting must not set an ErrorHandler on the mounted router, when parent has none")
+	}
+
+	// ErrorHandler set on the parent after mounting, is still used for errors of the mounted router
+	r.ErrorHandler = func(c *ivy.Context, err error) {
+		c.Status(http.StatusTeapot).SendString("root: " + err.Error())
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/fail", nil))
+	if w.Code != http.StatusTeapot || w.Body.String() != "root: EOF" {
+		t.Errorf("got %d %q", w.Code, w.Body.String())
+	}
+
+	// and a mounted router's own ErrorHandler takes precedence
+	sub.ErrorHandler = func(c *ivy.Context, err error) {
+		c.Status(http.StatusConflict).SendString("sub: " + err.Error())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/fail", nil))
+	if w.Code != http.StatusConflict || w.Body.String() != "sub: EOF" {
+		t.Errorf("got %d %q", w.Code, w.Body.String())
+	}
+}
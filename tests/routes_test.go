@@ -0,0 +1,90 @@
+package ivy_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func listUsers(c *ivy.Context) error {
+	return c.SendString("users")
+}
+
+func TestRoutes(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Use(func(c *ivy.Context) error { return c.Next() })
+
+	r.Get("/users", listUsers).Name("users.list").Tags("users")
+	r.Group("/admin", func(g *ivy.Router) {
+		g.Use(func(c *ivy.Context) error { return c.Next() })
+		g.Post("/reload", func(c *ivy.Context) error { return nil })
+	})
+
+	r2 := ivy.NewRouter()
+	r2.Use(func(c *ivy.Context) error { return c.Next() })
+	r2.Delete("/items/{id}", func(c *ivy.Context) error { return nil })
+	r.Mount("/v2", r2)
+
+	r.Handle("GET /raw", http.NotFoundHandler())
+
+	want := []ivy.RouteInfo{
+		{Method: http.MethodGet, Pattern: "/users", Name: "users.list", Tags: []string{"users"}, Middlewares: 1},
+		{Method: http.MethodPost, Pattern: "/admin/reload", Middlewares: 2},
+		{Method: http.MethodDelete, Pattern: "/v2/items/{id}", Middlewares: 2},
+		{Method: http.MethodGet, Pattern: "/raw", Middlewares: 1},
+	}
+
+	got := r.Routes()
+	if len(got) != len(want) {
+		t.Fatalf("routes: got %d, want %d (%+v)", len(got), len(want), got)
+	}
+
+	for i := range want {
+		if got[i].Method != want[i].Method || got[i].Pattern != want[i].Pattern || got[i].Name != want[i].Name || got[i].Middlewares != want[i].Middlewares {
+			t.Errorf("route[%d]: got %+v, want %+v", i, got[i], want[i])
+		}
+
+		if strings.Join(got[i].Tags, ",") != strings.Join(want[i].Tags, ",") {
+			t.Errorf("route[%d] tags: got %v, want %v", i, got[i].Tags, want[i].Tags)
+		}
+
+		if len(got[i].Handlers) != 1 {
+			t.Errorf("route[%d] handlers: got %v, want 1 handler", i, got[i].Handlers)
+		}
+	}
+
+	if !strings.HasSuffix(got[0].Handlers[0], "listUsers") {
+		t.Errorf("handler name: got %q, want suffix %q", got[0].Handlers[0], "listUsers")
+	}
+}
+
+func TestRoutesHandler(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Get("/users", listUsers).Name("users.list")
+	r.Get("/_debug/routes", r.RoutesHandler())
+
+	req := httptest.NewRequest(http.MethodGet, "/_debug/routes?format=json", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var routes []ivy.RouteInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &routes); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(routes) != 2 || routes[0].Name != "users.list" {
+		t.Errorf("routes: got %+v", routes)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/_debug/routes", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if !strings.Contains(w.Body.String(), "users.list") {
+		t.Errorf("text table: expected to contain route name, got %q", w.Body.String())
+	}
+}
@@ -0,0 +1,162 @@
+package ivy_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func TestServer(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	r := ivy.NewRouter()
+	srv := ivy.NewServer(r, ivy.ServerOptions{DrainDelay: 100 * time.Millisecond, DrainTimeout: 2 * time.Second})
+	r.Get("/_ready", srv.ReadinessHandler())
+	r.Get("/slow", func(c *ivy.Context) error {
+		close(started)
+		<-release
+		return c.SendString("finished")
+	})
+
+	var hooks []string
+	srv.OnStart(func(ctx context.Context) error {
+		hooks = append(hooks, "start")
+		return nil
+	})
+	srv.OnShutdown(func(ctx context.Context) error {
+		hooks = append(hooks, "shutdown 1")
+		return nil
+	})
+	srv.OnShutdown(func(ctx context.Context) error {
+		hooks = append(hooks, "shutdown 2")
+		return nil
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "http://" + ln.Addr().String()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, ln) }()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(url + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(b)
+	}
+
+	for !srv.Ready() {
+		time.Sleep(time.Millisecond)
+	}
+	if code, _ := get("/_ready"); code != http.StatusOK {
+		t.Errorf("readiness: got %d, want 200", code)
+	}
+
+	slow := make(chan string, 1)
+	go func() {
+		_, body := get("/slow")
+		slow <- body
+	}()
+	<-started
+
+	cancel()
+
+	// INFO: during DrainDelay, new requests are still served, with readiness failing
+	time.Sleep(20 * time.Millisecond)
+	if code, _ := get("/_ready"); code != http.StatusServiceUnavailable {
+		t.Errorf("readiness while draining: got %d, want 503", code)
+	}
+
+	close(release)
+	if body := <-slow; body != "finished" {
+		t.Errorf("in-flight request: got %q", body)
+	}
+
+	if err := <-done; err != nil {
+		t.Errorf("serve: %v", err)
+	}
+	if got := strings.Join(hooks, ", "); got != "start, shutdown 2, shutdown 1" {
+		t.Errorf("hooks: got %q", got)
+	}
+}
+
+func TestServer_DrainTimeout(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Get("/stuck", func(c *ivy.Context) error {
+		<-c.Done()
+		return nil
+	})
+
+	srv := ivy.NewServer(r, ivy.ServerOptions{DrainTimeout: 50 * time.Millisecond})
+
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, ln) }()
+
+	for !srv.Ready() {
+		time.Sleep(time.Millisecond)
+	}
+	go http.Get("http://" + ln.Addr().String() + "/stuck")
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	if err := <-done; err == nil || !strings.Contains(err.Error(), "draining requests") {
+		t.Errorf("expected drain timeout error, got %v", err)
+	}
+}
+
+func TestServer_UnixSocket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "ivy.sock")
+
+	r := ivy.NewRouter()
+	r.Get("/", func(c *ivy.Context) error { return c.SendString("over unix") })
+	srv := ivy.NewServer(r, ivy.ServerOptions{Addr: "unix:" + sock})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.ListenAndServe(ctx) }()
+
+	for !srv.Ready() {
+		time.Sleep(time.Millisecond)
+	}
+
+	client := http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return net.Dial("unix", sock)
+		},
+	}}
+	resp, err := client.Get("http://ivy/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(b) != "over unix" {
+		t.Errorf("body: got %q", b)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("serve: %v", err)
+	}
+	if _, err := os.Stat(sock); !os.IsNotExist(err) {
+		t.Errorf("socket file must be removed on shutdown, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package ivy_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func TestSSE(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Get("/events", func(c *ivy.Context) error {
+		stream := c.SSE(ivy.SSEOptions{Heartbeat: -1})
+		if err := stream.Send(ivy.Event{ID: stream.LastEventID() + "1", Event: "progress", Data: "line 1\nline 2", Retry: 3 * time.Second}); err != nil {
+			return err
+		}
+		return stream.Send(ivy.Event{Data: "done"})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set("Last-Event-ID", "4")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("content type: got %q", ct)
+	}
+
+	want := "id: 41\nevent: progress\nretry: 3000\ndata: line 1\ndata: line 2\n\ndata: done\n\n"
+	if w.Body.String() != want {
+		t.Errorf("body: got %q, want %q", w.Body.String(), want)
+	}
+}
+
+func TestSSEHeartbeatAndDisconnect(t *testing.T) {
+	finished := make(chan error, 1)
+
+	r := ivy.NewRouter()
+	r.Get("/events", func(c *ivy.Context) error {
+		stream := c.SSE(ivy.SSEOptions{Heartbeat: 10 * time.Millisecond})
+		stream.Send(ivy.Event{Data: "hello"})
+
+		<-stream.Done()
+		finished <- stream.Send(ivy.Event{Data: "too late"})
+		return nil
+	})
+
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	br := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 4 {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, line)
+	}
+
+	if got := strings.Join(lines, ""); got != "data: hello\n\n: heartbeat\n\n" {
+		t.Errorf("stream: got %q", got)
+	}
+
+	cancel()
+
+	select {
+	case err := <-finished:
+		if err == nil {
+			t.Errorf("send after disconnect must fail")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler did not notice client disconnect")
+	}
+}
@@ -0,0 +1,167 @@
+package ivy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+func TestRouteTimeout(t *testing.T) {
+	lateWrite := make(chan error, 1)
+
+	r := ivy.NewRouter()
+	r.Get("/fast", func(c *ivy.Context) error {
+		c.SetHeader("X-Handler", "fast")
+		return c.Status(http.StatusCreated).SendString("done")
+	}).Timeout(time.Second)
+
+	r.Get("/slow", func(c *ivy.Context) error {
+		c.SendString("partial")
+		<-c.Done()
+		time.Sleep(10 * time.Millisecond)
+		lateWrite <- c.SendString("late")
+		return c.Err()
+	}).Timeout(20 * time.Millisecond)
+
+	r.Get("/stream", func(c *ivy.Context) error {
+		c.SendString("first\n")
+		c.Flush()
+		<-c.Done()
+		time.Sleep(10 * time.Millisecond)
+		lateWrite <- c.SendString("late\n")
+		return nil
+	}).Timeout(20 * time.Millisecond)
+
+	t.Run("handler returning in time", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+		if w.Code != http.StatusCreated || w.Body.String() != "done" || w.Header().Get("X-Handler") != "fast" {
+			t.Errorf("got %d %q %v", w.Code, w.Body.String(), w.Header())
+		}
+	})
+
+	t.Run("handler running past its timeout", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("status code: got %d, want %d", w.Code, http.StatusServiceUnavailable)
+		}
+
+		if err := <-lateWrite; !errors.Is(err, http.ErrHandlerTimeout) {
+			t.Errorf("write after timeout: got %v", err)
+		}
+		if got := w.Body.String(); got != "Service Unavailable\n" {
+			t.Errorf("buffered response must be dropped, got %q", got)
+		}
+	})
+
+	t.Run("streaming handler", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream", nil))
+		if w.Code != http.StatusOK || !w.Flushed {
+			t.Errorf("got %d, flushed %v", w.Code, w.Flushed)
+		}
+
+		if err := <-lateWrite; !errors.Is(err, http.ErrHandlerTimeout) {
+			t.Errorf("write after timeout: got %v", err)
+		}
+		if got := w.Body.String(); got != "first\n" {
+			t.Errorf("body: got %q", got)
+		}
+	})
+}
+
+func TestRouteTimeout_ErrorHandler(t *testing.T) {
+	var gotErr error
+
+	r := ivy.NewRouter()
+	r.ErrorHandler = func(c *ivy.Context, err error) {
+		gotErr = err
+		c.Status(http.StatusGatewayTimeout).SendString("timed out")
+	}
+	r.Use(func(c *ivy.Context) error {
+		defer func() {
+			if p := recover(); p != nil {
+				c.Status(http.StatusTeapot)
+			}
+		}()
+		return c.Next()
+	})
+	r.Get("/slow", func(c *ivy.Context) error {
+		<-c.Done()
+		return nil
+	}).Timeout(10 * time.Millisecond)
+	r.Get("/panic", func(c *ivy.Context) error {
+		panic("boom")
+	}).Timeout(time.Second)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if !errors.Is(gotErr, context.DeadlineExceeded) {
+		t.Errorf("error handler got %v", gotErr)
+	}
+	if w.Code != http.StatusGatewayTimeout || w.Body.String() != "timed out" {
+		t.Errorf("got %d %q", w.Code, w.Body.String())
+	}
+
+	// panics of handlers are raised on the request goroutine
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if w.Code != http.StatusTeapot {
+		t.Errorf("panic: got %d", w.Code)
+	}
+}
+
+func TestRouteTimeout_KV(t *testing.T) {
+	lateSet := make(chan struct{})
+
+	r := ivy.NewRouter()
+	r.ErrorHandler = func(c *ivy.Context, err error) {
+		// INFO: handler keeps setting KV past its timeout, which must not race with reading it here
+		for range 100 {
+			c.KV.Get("user")
+		}
+		c.Status(http.StatusServiceUnavailable).SendString(fmt.Sprint(c.KV.Get("user")))
+	}
+	r.Get("/fast", func(c *ivy.Context) error {
+		c.KV.Set("user", "alice")
+		return nil
+	}).Timeout(time.Second)
+	r.Get("/slow", func(c *ivy.Context) error {
+		<-c.Done()
+		for i := range 100 {
+			c.KV.Set("user", i)
+		}
+		close(lateSet)
+		return nil
+	}).Timeout(10 * time.Millisecond)
+
+	var got any
+	r2 := ivy.NewRouter()
+	r2.Use(func(c *ivy.Context) error {
+		c.KV.Set("user", "anonymous")
+		err := c.Next()
+		got = c.KV.Get("user")
+		return err
+	})
+	r2.Mount("/api", r)
+
+	// what handlers set is kept, when they return in time
+	r2.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/fast", nil))
+	if got != "alice" {
+		t.Errorf("KV after handler returned in time: got %v", got)
+	}
+
+	w := httptest.NewRecorder()
+	r2.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/slow", nil))
+	<-lateSet
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != "anonymous" || got != "anonymous" {
+		t.Errorf("what handler set after timeout must be dropped, got %d %q, and %v", w.Code, w.Body.String(), got)
+	}
+}
@@ -0,0 +1,193 @@
+package ivy_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+type CreateItemRequest struct {
+	OrgID  int    `path:"org" json:"-"`
+	DryRun bool   `query:"dry_run" json:"-"`
+	Name   string `json:"name" validate:"required"`
+}
+
+type CreatedItem struct {
+	ID    string `json:"id"`
+	OrgID int    `json:"org_id"`
+	Name  string `json:"name"`
+}
+
+func (CreatedItem) StatusCode() int {
+	return http.StatusCreated
+}
+
+type NoContent struct{}
+
+func (NoContent) StatusCode() int {
+	return http.StatusNoContent
+}
+
+func createItem(c *ivy.Context, in CreateItemRequest) (CreatedItem, error) {
+	if in.DryRun {
+		return CreatedItem{}, ivy.NewHTTPError(http.StatusConflict, "dry run")
+	}
+	return CreatedItem{ID: "1", OrgID: in.OrgID, Name: in.Name}, nil
+}
+
+func TestTypedHandler(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Post("/orgs/{org}/items", ivy.Typed(createItem))
+	r.Delete("/items/{id}", ivy.Typed(func(c *ivy.Context, in struct {
+		ID string `path:"id"`
+	}) (NoContent, error) {
+		if in.ID != "1" {
+			return NoContent{}, errors.New("unexpected id " + in.ID)
+		}
+		return NoContent{}, nil
+	}))
+	r.Get("/items", ivy.Typed(func(c *ivy.Context, in struct {
+		Limit int `query:"limit" default:"2"`
+	}) ([]string, error) {
+		return []string{"a", "b", "c"}[:in.Limit], nil
+	}))
+
+	tests := []struct {
+		name       string
+		method     string
+		route      string
+		body       string
+		accept     string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "1. [Typed] body, path and query are bound, and status comes from StatusCoder",
+			method:     http.MethodPost,
+			route:      "/orgs/7/items",
+			body:       `{"name":"ivy"}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"id":"1","org_id":7,"name":"ivy"}`,
+		},
+		{
+			name:       "2. [Typed] validation error",
+			method:     http.MethodPost,
+			route:      "/orgs/7/items",
+			body:       `{}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   "validation failed: body \"name\": is required\n",
+		},
+		{
+			name:       "3. [Typed] bind error",
+			method:     http.MethodPost,
+			route:      "/orgs/abc/items",
+			body:       `{"name":"ivy"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "4. [Typed] handler error",
+			method:     http.MethodPost,
+			route:      "/orgs/7/items?dry_run=true",
+			body:       `{"name":"ivy"}`,
+			wantStatus: http.StatusConflict,
+			wantBody:   "dry run\n",
+		},
+		{
+			name:       "5. [Typed] no content",
+			method:     http.MethodDelete,
+			route:      "/items/1",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "6. [Typed] response is negotiated",
+			method:     http.MethodGet,
+			route:      "/items",
+			accept:     "application/x-ndjson",
+			wantStatus: http.StatusOK,
+			wantBody:   "\"a\"\n\"b\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.route, bytes.NewReader([]byte(tt.body)))
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status code: got %d, want %d (body: %q)", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body: got %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestTypedHandlerForm(t *testing.T) {
+	type search struct {
+		Page  int    `form:"page" default:"1"`
+		Name  string `form:"name"`
+		Limit int    `query:"limit" default:"10"`
+	}
+
+	r := ivy.NewRouter()
+	r.Post("/search", ivy.Typed(func(c *ivy.Context, in search) (search, error) {
+		return in, nil
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader("page=5&name=bob"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// values from the body must not be replaced by defaults, which still apply to other sources
+	if want := `{"Page":5,"Name":"bob","Limit":10}`; w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != want {
+		t.Errorf("got %d %s, want %s", w.Code, w.Body.String(), want)
+	}
+}
+
+func TestTypedHandlerRouteInfo(t *testing.T) {
+	r := ivy.NewRouter()
+	ivy.TypedRoute(r.Post, "/items", createItem)
+	r.Get("/plain", func(c *ivy.Context) error { return nil })
+	r.Put("/typed", ivy.Typed(createItem))
+	r.Post("/uploads", func(c *ivy.Context) error { return nil }).Types(reflect.TypeFor[CreateItemRequest](), nil)
+
+	routes := r.Routes()
+
+	if routes[0].Request != reflect.TypeFor[CreateItemRequest]() {
+		t.Errorf("request type: got %v", routes[0].Request)
+	}
+
+	if routes[0].Response != reflect.TypeFor[CreatedItem]() {
+		t.Errorf("response type: got %v", routes[0].Response)
+	}
+
+	if !strings.HasSuffix(routes[0].Handlers[0], "createItem") {
+		t.Errorf("handler name: got %q", routes[0].Handlers[0])
+	}
+
+	if routes[1].Request != nil || routes[1].Response != nil {
+		t.Errorf("plain handler must not have types, got %v, %v", routes[1].Request, routes[1].Response)
+	}
+
+	// types are only recorded with TypedRoute (or Route.Types)
+	if routes[2].Request != nil || routes[2].Response != nil {
+		t.Errorf("Typed handler registered directly must not have types, got %v, %v", routes[2].Request, routes[2].Response)
+	}
+
+	if routes[3].Request != reflect.TypeFor[CreateItemRequest]() || routes[3].Response != nil {
+		t.Errorf("types set with Route.Types: got %v, %v", routes[3].Request, routes[3].Response)
+	}
+}
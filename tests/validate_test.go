@@ -0,0 +1,140 @@
+package ivy_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+type Address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type CreateUser struct {
+	Name      string    `json:"name" validate:"required,max=8"`
+	Email     string    `json:"email" validate:"required,email"`
+	Age       int       `json:"age" validate:"min=18,max=130"`
+	Role      string    `json:"role" validate:"omitempty,oneof=admin member"`
+	Website   string    `json:"website" validate:"omitempty,url"`
+	Tags      []string  `json:"tags" validate:"max=2"`
+	Addresses []Address `json:"addresses"`
+	Password  string    `json:"password"`
+	Confirm   string    `json:"confirm"`
+}
+
+func (u CreateUser) Validate() error {
+	if u.Password != u.Confirm {
+		return &ivy.FieldError{Field: "confirm", Err: errors.New("must match password")}
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantErrors []string
+	}{
+		{
+			name:       "1. [Validate] valid body",
+			body:       `{"name":"ivy","email":"ivy@example.com","age":20,"role":"admin","addresses":[{"city":"Delhi"}]}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "2. [Validate] per field errors",
+			body:       `{"name":"a very long name","email":"not-an-email","age":12,"role":"root","website":"example","tags":["a","b","c"],"addresses":[{"city":""}],"password":"a","confirm":"b"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrors: []string{
+				`body "name": must have length <= 8`,
+				`body "email": must be a valid email address`,
+				`body "age": must be >= 18`,
+				`body "role": must be one of [admin member]`,
+				`body "website": must be a valid URL`,
+				`body "tags": must have length <= 2`,
+				`body "addresses[0].city": is required`,
+				`body "confirm": must match password`,
+			},
+		},
+		{
+			name:       "3. [Validate] required fields",
+			body:       `{"age":18}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErrors: []string{
+				`body "name": is required`,
+				`body "email": is required`,
+			},
+		},
+	}
+
+	r := ivy.NewRouter()
+	r.ErrorHandler = func(c *ivy.Context, err error) {
+		code := http.StatusInternalServerError
+		if he, ok := err.(ivy.HTTPError); ok {
+			code = he.Code()
+		}
+		c.Status(code).SendJSON(ivy.ErrorFormatJSON(err))
+	}
+	r.Post("/users", func(c *ivy.Context) error {
+		var in CreateUser
+		if err := c.ParseBodyInto(&in); err != nil {
+			return err
+		}
+		return c.SendString("OK")
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewReader([]byte(tt.body)))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status code: got %d, want %d (body: %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			if tt.wantErrors == nil {
+				return
+			}
+
+			var body struct {
+				Errors []string `json:"errors"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+
+			if strings.Join(body.Errors, "\n") != strings.Join(tt.wantErrors, "\n") {
+				t.Errorf("errors:\n\t got: %q\n\twant: %q", body.Errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestBindRunsValidation(t *testing.T) {
+	r := ivy.NewRouter()
+	r.Get("/", func(c *ivy.Context) error {
+		var in struct {
+			Page int `query:"page" validate:"min=1"`
+		}
+		return c.Bind(&in)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/?page=0", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status code: got %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+
+	if want := "validation failed: query \"page\": must be >= 1\n"; w.Body.String() != want {
+		t.Errorf("body: got %q, want %q", w.Body.String(), want)
+	}
+}
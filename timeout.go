@@ -0,0 +1,290 @@
+package ivy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// NextWithTimeout calls the next handlers in chain (like [Context.Next]), with request context that is cancelled after d.
+//
+// Response of the next handlers is buffered, and only written once they return in time. Otherwise, they are left running
+// with a cancelled context, their writes fail with [http.ErrHandlerTimeout], and [context.DeadlineExceeded] is returned,
+// which router's ErrorHandler gets to respond to (DefaultErrorHandler responds with 503 Service Unavailable).
+//
+// Next handlers get a copy of c, with its own KV store, whose changes (along with c.Logger) are only kept, when they return in time.
+// Their panics are re-raised on the calling goroutine, as a [*GoroutinePanic] carrying their stack trace.
+//
+// Streaming responses are written out, as soon as they are flushed, and then the deadline is set as write deadline
+// of the connection (with [http.ResponseController]), so that writes after it fail.
+//
+// See middleware.Timeout, and [Route.Timeout] for a per route timeout.
+func (c *Context) NextWithTimeout(d time.Duration) error {
+	return c.runWithTimeout(d, func(hc *Context) error {
+		return hc.Next()
+	})
+}
+
+// runWithTimeout runs fn in a goroutine, with a copy of c, that has its own response writer and request context
+func (c *Context) runWithTimeout(d time.Duration, fn func(hc *Context) error) error {
+	ctx, cancel := context.WithTimeout(c.request.Context(), d)
+	defer cancel()
+
+	deadline, _ := ctx.Deadline()
+	tw := newTimeoutWriter(c.response, deadline)
+
+	// INFO: handlers keep running after timeout, so they get their own copy of c, leaving c to the ErrorHandler.
+	// Their copy has its own KV store, route pattern and response state, which are copied back to c, only when they return in time
+	hc := *c
+	hc.cleanups = nil
+	hc.writer = NewResponseWriter(tw)
+	hc.response = hc.writer
+	hc.KV = &KV{m: maps.Clone(c.KV.m)}
+	hc.pattern = &routePattern{value: c.pattern.value}
+	hc.SetContext(context.WithValue(context.WithValue(ctx, kvCtxKey, hc.KV), patternCtxKey, hc.pattern))
+
+	type result struct {
+		err       error
+		panicking bool
+		panicVal  any
+		stack     []byte
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		res := result{panicking: true}
+		defer func() {
+			if res.panicking {
+				res.panicVal = recover()
+				res.stack = debug.Stack()
+			}
+			done <- res
+		}()
+
+		// cleanups registered by the handlers (like stopping SSE heartbeats) run, once they return
+		defer hc.runCleanups()
+		res.err = fn(&hc)
+		res.panicking = false
+	}()
+
+	select {
+	case res := <-done:
+		if res.panicking {
+			tw.close()
+			if err, ok := res.panicVal.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(res.panicVal)
+			}
+			// INFO: re-panicking on the request goroutine, so that middlewares like Recoverer see it, along with where it happened
+			panic(&GoroutinePanic{Value: res.panicVal, Stack: res.stack})
+		}
+
+		// handlers returned in time, so what they have set on their copy of c is kept
+		*c.KV = *hc.KV
+		c.pattern.value = hc.pattern.value
+		c.Logger = hc.Logger
+		c.afterResponse = hc.afterResponse
+
+		if err := tw.finish(res.err != nil); err != nil && res.err == nil {
+			return err
+		}
+		return res.err
+	case <-ctx.Done():
+		tw.close()
+		if c.request.Context().Err() != nil {
+			// request got cancelled (i.e. client went away), before the deadline
+			return c.request.Context().Err()
+		}
+		return context.DeadlineExceeded
+	}
+}
+
+// GoroutinePanic is what handlers, that [Context.NextWithTimeout] runs in a goroutine of their own, are re-panicked with
+// on the request goroutine, so that the stack trace of where they panicked is kept (middleware.Recoverer reports it).
+// Panics with [http.ErrAbortHandler] are re-panicked as is.
+type GoroutinePanic struct {
+	// Value is the value, that panic was called with
+	Value any
+
+	// Stack is the stack trace of the goroutine, that panicked
+	Stack []byte
+}
+
+// Error implements error, it includes the stack trace, as net/http logs only the stack of the request goroutine for unrecovered panics
+func (p *GoroutinePanic) Error() string {
+	return fmt.Sprintf("panic: %v\n\n%s", p.Value, p.Stack)
+}
+
+// Unwrap returns panic value, if it is an error
+func (p *GoroutinePanic) Unwrap() error {
+	err, _ := p.Value.(error)
+	return err
+}
+
+// timeoutWriter buffers the response, until handlers return, or it gets flushed, after which it writes through
+type timeoutWriter struct {
+	w        http.ResponseWriter
+	deadline time.Time
+
+	mu     sync.Mutex
+	header http.Header
+	status int
+	buf    []byte
+
+	// streaming is set, once the response is flushed (or the connection hijacked), and is no longer buffered
+	streaming bool
+	closed    bool
+}
+
+func newTimeoutWriter(w http.ResponseWriter, deadline time.Time) *timeoutWriter {
+	return &timeoutWriter{w: w, deadline: deadline, header: w.Header().Clone()}
+}
+
+// Header implements http.ResponseWriter.
+func (tw *timeoutWriter) Header() http.Header {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.streaming {
+		return tw.w.Header()
+	}
+	return tw.header
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.closed {
+		return
+	}
+	if tw.streaming {
+		tw.w.WriteHeader(code)
+		return
+	}
+	if tw.status == 0 && (code >= 200 || code == http.StatusSwitchingProtocols) {
+		tw.status = code
+	}
+}
+
+// Write implements http.ResponseWriter.
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.closed {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.streaming {
+		return tw.w.Write(b)
+	}
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+	tw.buf = append(tw.buf, b...)
+	return len(b), nil
+}
+
+// FlushError writes out the buffered response, and switches to writing through, with the deadline as write deadline
+func (tw *timeoutWriter) FlushError() error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.closed {
+		return http.ErrHandlerTimeout
+	}
+
+	if !tw.streaming {
+		if err := tw.writeBuffered(); err != nil {
+			return err
+		}
+		tw.streaming = true
+
+		if err := http.NewResponseController(tw.w).SetWriteDeadline(tw.deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+	}
+	return http.NewResponseController(tw.w).Flush()
+}
+
+// Hijack implements http.Hijacker.
+func (tw *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.closed {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+
+	conn, brw, err := http.NewResponseController(tw.w).Hijack()
+	if err == nil {
+		tw.streaming = true
+	}
+	return conn, brw, err
+}
+
+// Unwrap returns the underlying writer
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.w
+}
+
+// writeBuffered writes buffered headers and body to the underlying writer
+func (tw *timeoutWriter) writeBuffered() error {
+	dst := tw.w.Header()
+	for k := range dst {
+		if _, ok := tw.header[k]; !ok {
+			delete(dst, k)
+		}
+	}
+	for k, v := range tw.header {
+		dst[k] = v
+	}
+
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+	tw.w.WriteHeader(tw.status)
+
+	if len(tw.buf) == 0 {
+		return nil
+	}
+	_, err := tw.w.Write(tw.buf)
+	tw.buf = nil
+	return err
+}
+
+// finish writes out the response of handlers, that returned in time. On failure, only headers are kept, for ErrorHandler to respond
+func (tw *timeoutWriter) finish(failed bool) error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.closed = true
+	if tw.streaming {
+		return nil
+	}
+
+	if failed || tw.status == 0 {
+		dst := tw.w.Header()
+		for k, v := range tw.header {
+			dst[k] = v
+		}
+		return nil
+	}
+	return tw.writeBuffered()
+}
+
+// close makes further writes of the handlers fail, and drops the buffered body
+func (tw *timeoutWriter) close() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.closed = true
+	tw.buf = nil
+}
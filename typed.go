@@ -0,0 +1,89 @@
+package ivy
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+)
+
+// StatusCoder can be implemented by responses of [Typed] handlers, to set the response status code
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// TypedFunc is a handler, that receives its decoded request input, and returns the response to be encoded
+type TypedFunc[Req any, Resp any] func(c *Context, in Req) (Resp, error)
+
+// Typed adapts fn into a Handler, which
+//   - decodes request body into Req (with [Context.ParseBodyInto] codecs), when request has a body
+//   - binds path, query, header, cookie and form values into Req (with [Context.Bind] struct tags), when Req is a struct.
+//     Once the body is decoded, form values are not bound again, and defaults do not override fields set from the body
+//   - validates Req with [Validate]
+//   - calls fn, and encodes its response with [Context.Send], using status code from [StatusCoder] if Resp implements it
+//
+// Register it with [TypedRoute], to have request and response types recorded on the route, for [Router.Routes] and [Router.OpenAPI]
+//
+// Example:
+//
+//	r.Post("/users/{org}", ivy.Typed(func(c *ivy.Context, in CreateUser) (User, error) {
+//	    return svc.CreateUser(c, in)
+//	}))
+func Typed[Req any, Resp any](fn TypedFunc[Req, Resp]) Handler {
+	return func(c *Context) error {
+		var in Req
+
+		rv := reflect.ValueOf(&in)
+
+		decoded := false
+		if c.request.ContentLength != 0 && c.request.Body != nil && c.request.Body != http.NoBody {
+			if err := c.decodeBody(&in); err != nil {
+				return err
+			}
+			decoded = true
+		}
+
+		if rv.Elem().Kind() == reflect.Struct {
+			if err := c.bind(rv, decoded); err != nil {
+				return err
+			}
+		}
+
+		if err := Validate(&in); err != nil {
+			return err
+		}
+
+		out, err := fn(c, in)
+		if err != nil {
+			return err
+		}
+
+		code := 0
+		if sc, ok := any(out).(StatusCoder); ok {
+			code = sc.StatusCode()
+		}
+
+		if code == http.StatusNoContent || code == http.StatusNotModified {
+			return c.SendStatus(code)
+		}
+
+		return c.sendWithStatus(code, out)
+	}
+}
+
+// TypedRoute registers fn (adapted with [Typed]) with register (like r.Post), after middlewares,
+// and records its request and response types on the route (see [Route.Types])
+//
+// Example:
+//
+//	ivy.TypedRoute(r.Post, "/users/{org}", func(c *ivy.Context, in CreateUser) (User, error) {
+//	    return svc.CreateUser(c, in)
+//	}, authMiddleware)
+func TypedRoute[Req any, Resp any](register func(path string, handlers ...Handler) *Route, path string, fn TypedFunc[Req, Resp], middlewares ...Handler) *Route {
+	route := register(path, append(slices.Clone(middlewares), Typed(fn))...)
+
+	// INFO: the handler shows up by the name of fn, rather than that of the closure created by Typed
+	if len(route.handlers) > 0 {
+		route.handlers[len(route.handlers)-1] = handlerName(fn)
+	}
+	return route.Types(reflect.TypeFor[Req](), reflect.TypeFor[Resp]())
+}
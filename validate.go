@@ -0,0 +1,303 @@
+package ivy
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validator can be implemented by request structs, to validate things that validate tags can not express.
+// It runs after the tag based validation, by [Validate], [Context.Bind] and [Context.ParseBodyInto]
+//
+// Returned [FieldError]s (also when joined with [errors.Join]) are listed per field, in the resulting HTTPError
+type Validator interface {
+	Validate() error
+}
+
+// Validate validates v, as per `validate` tags on its struct fields, and then with its [Validator] implementation (if any).
+// On failure, it returns [HTTPError] with status code 422, that has a [FieldError] for every invalid field.
+//
+// Supported rules:
+//   - required: value must not be the zero value
+//   - omitempty: skip other rules, when value is the zero value
+//   - min=n, max=n: bounds for numbers, and for length of strings, slices and maps
+//   - len=n: exact length of strings, slices and maps
+//   - email, url, uuid: string formats
+//   - oneof=a b c: value must be one of the space separated values
+//
+// Example:
+//
+//	type CreateUser struct {
+//	    Name  string `json:"name" validate:"required,max=64"`
+//	    Email string `json:"email" validate:"required,email"`
+//	    Role  string `json:"role" validate:"oneof=admin member"`
+//	}
+func Validate(v any) error {
+	var errs []error
+	if err := validateValue(reflect.ValueOf(v), "", "", &errs); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return NewHTTPErrors(http.StatusUnprocessableEntity, "validation failed", errs...)
+	}
+
+	return nil
+}
+
+func validateValue(v reflect.Value, path string, source string, errs *[]error) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if err := validateStruct(v, path, source, errs); err != nil {
+			return err
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validateValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), source, errs); err != nil {
+				return err
+			}
+		}
+	}
+
+	if v.CanAddr() && v.Addr().CanInterface() {
+		if validator, ok := v.Addr().Interface().(Validator); ok {
+			collectValidatorErrors(validator.Validate(), path, source, errs)
+			return nil
+		}
+	}
+
+	if v.CanInterface() {
+		if validator, ok := v.Interface().(Validator); ok {
+			collectValidatorErrors(validator.Validate(), path, source, errs)
+		}
+	}
+
+	return nil
+}
+
+func collectValidatorErrors(err error, path string, source string, errs *[]error) {
+	if err == nil {
+		return
+	}
+
+	if je, ok := err.(joinErrors); ok {
+		for _, e := range je.Unwrap() {
+			collectValidatorErrors(e, path, source, errs)
+		}
+		return
+	}
+
+	var fe *FieldError
+	if errors.As(err, &fe) {
+		fe = &FieldError{Field: fe.Field, Source: fe.Source, Err: fe.Err}
+		if path != "" {
+			fe.Field = path + "." + fe.Field
+		}
+		if fe.Source == "" {
+			fe.Source = cmp.Or(source, "body")
+		}
+		*errs = append(*errs, fe)
+		return
+	}
+
+	*errs = append(*errs, err)
+}
+
+func validateStruct(v reflect.Value, path string, source string, errs *[]error) error {
+	// types like time.Time, are validated as a whole
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return nil
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fv := v.Field(i)
+
+		if sf.Anonymous && !hasBindTag(sf) && sf.Tag.Get("validate") == "" {
+			if err := validateValue(fv, path, source, errs); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, fieldSource := fieldName(sf, source)
+		if name == "-" {
+			continue
+		}
+
+		if path != "" {
+			name = path + "." + name
+		}
+
+		if rules := sf.Tag.Get("validate"); rules != "" && rules != "-" {
+			msg, err := checkRules(fv, rules)
+			if err != nil {
+				return fmt.Errorf("ivy: field %s.%s: %w", t.Name(), sf.Name, err)
+			}
+			if msg != "" {
+				*errs = append(*errs, &FieldError{Field: name, Source: fieldSource, Err: errors.New(msg)})
+				continue
+			}
+		}
+
+		if err := validateValue(fv, name, fieldSource, errs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fieldName returns name of the field, as clients see it, i.e. from its bind tag, or json tag
+func fieldName(sf reflect.StructField, source string) (string, string) {
+	if bindSource, name := bindTag(sf); bindSource != "" {
+		return name, bindSource
+	}
+
+	if source == "" {
+		source = "body"
+	}
+
+	if name, _, _ := strings.Cut(sf.Tag.Get("json"), ","); name != "" {
+		return name, source
+	}
+
+	return sf.Name, source
+}
+
+// checkRules returns a message describing the first failed rule, and an error for invalid rules
+func checkRules(v reflect.Value, rules string) (string, error) {
+	isZero := v.IsZero()
+
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			break
+		}
+		v = v.Elem()
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "required":
+			if isZero {
+				return "is required", nil
+			}
+		case "omitempty":
+			if isZero {
+				return "", nil
+			}
+		case "min", "max", "len":
+			if v.Kind() == reflect.Pointer {
+				continue
+			}
+
+			msg, err := checkBound(v, name, param)
+			if err != nil || msg != "" {
+				return msg, err
+			}
+		case "email":
+			if s, ok := stringValue(v); ok {
+				if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+					return "must be a valid email address", nil
+				}
+			}
+		case "url":
+			if s, ok := stringValue(v); ok {
+				if u, err := url.ParseRequestURI(s); err != nil || u.Scheme == "" || u.Host == "" {
+					return "must be a valid URL", nil
+				}
+			}
+		case "uuid":
+			if s, ok := stringValue(v); ok {
+				if _, err := ParseUUID(s); err != nil {
+					return "must be a valid UUID", nil
+				}
+			}
+		case "oneof":
+			if v.Kind() == reflect.Pointer {
+				continue
+			}
+			options := strings.Fields(param)
+			if !slices.Contains(options, fmt.Sprint(reflect.Indirect(v))) {
+				return fmt.Sprintf("must be one of [%s]", strings.Join(options, " ")), nil
+			}
+		default:
+			return "", fmt.Errorf("unknown validation rule %q", name)
+		}
+	}
+
+	return "", nil
+}
+
+func stringValue(v reflect.Value) (string, bool) {
+	if v.Kind() != reflect.String || v.Len() == 0 {
+		return "", false
+	}
+	return v.String(), true
+}
+
+func checkBound(v reflect.Value, rule string, param string) (string, error) {
+	bound, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid param %q for rule %q", param, rule)
+	}
+
+	var n float64
+	isLength := false
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	case reflect.String:
+		n, isLength = float64(utf8.RuneCountInString(v.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n, isLength = float64(v.Len()), true
+	default:
+		return "", fmt.Errorf("rule %q is not supported for %s", rule, v.Type())
+	}
+
+	subject := "must be"
+	if isLength {
+		subject = "must have length"
+	}
+
+	switch {
+	case rule == "min" && n < bound:
+		return fmt.Sprintf("%s >= %s", subject, param), nil
+	case rule == "max" && n > bound:
+		return fmt.Sprintf("%s <= %s", subject, param), nil
+	case rule == "len" && (!isLength || n != bound):
+		if !isLength {
+			return "", fmt.Errorf("rule %q is not supported for %s", rule, v.Type())
+		}
+		return fmt.Sprintf("%s %s", subject, param), nil
+	}
+
+	return "", nil
+}
@@ -0,0 +1,601 @@
+package ws
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// MessageType is the type of a data message
+type MessageType int
+
+const (
+	TextMessage   MessageType = 1
+	BinaryMessage MessageType = 2
+)
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+// StatusCode is the status code of a close frame
+type StatusCode int
+
+const (
+	StatusNormalClosure    StatusCode = 1000
+	StatusGoingAway        StatusCode = 1001
+	StatusProtocolError    StatusCode = 1002
+	StatusUnsupportedData  StatusCode = 1003
+	StatusNoStatusReceived StatusCode = 1005
+	StatusAbnormalClosure  StatusCode = 1006
+	StatusInvalidPayload   StatusCode = 1007
+	StatusPolicyViolation  StatusCode = 1008
+	StatusMessageTooBig    StatusCode = 1009
+	StatusMandatoryExt     StatusCode = 1010
+	StatusInternalError    StatusCode = 1011
+)
+
+// CloseError is returned by reads, once the connection has been closed with a close frame.
+// It is the close frame of the peer, or the one sent on a protocol violation by the peer
+type CloseError struct {
+	Code   StatusCode
+	Reason string
+}
+
+// Error implements error.
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket: closed with status %d", e.Code)
+	}
+	return fmt.Sprintf("websocket: closed with status %d: %s", e.Code, e.Reason)
+}
+
+// CloseStatus returns status code of err, if it is a [CloseError], and -1 otherwise
+func CloseStatus(err error) StatusCode {
+	var ce *CloseError
+	if errors.As(err, &ce) {
+		return ce.Code
+	}
+	return -1
+}
+
+// ErrClosed is returned by writes, once a close frame has been sent
+var ErrClosed = errors.New("websocket: connection closed")
+
+var errMessageTooBig = &CloseError{Code: StatusMessageTooBig, Reason: "message too big"}
+
+// closeTimeout is how long Close waits for the peer's close frame
+const closeTimeout = 5 * time.Second
+
+// fragmentSize is the maximum payload of a data frame, bigger messages are sent as multiple fragments
+const fragmentSize = 32 << 10
+
+// Conn is a WebSocket connection.
+//
+// Reads must happen from one goroutine at a time, while writes can happen concurrently.
+// Pings are answered, while reading messages, so connections must be read from, for control frames to be processed.
+type Conn struct {
+	conn net.Conn
+	br   *bufio.Reader
+
+	// server sends unmasked frames, and expects masked ones
+	server      bool
+	compress    bool
+	readLimit   int64
+	subprotocol string
+
+	readMu  sync.Mutex
+	readErr error
+	// readDone is closed, once reads have failed (with a close frame or otherwise)
+	readDone chan struct{}
+
+	// msgMu is held while a data message is being written, control frames can still go in between its fragments
+	msgMu sync.Mutex
+
+	writeMu   sync.Mutex
+	bw        *bufio.Writer
+	closeSent bool
+
+	closeOnce   sync.Once
+	pongHandler func(data []byte)
+}
+
+func newConn(conn net.Conn, brw *bufio.ReadWriter, server bool, compress bool, readLimit int64) *Conn {
+	return &Conn{
+		conn:      conn,
+		br:        brw.Reader,
+		bw:        brw.Writer,
+		server:    server,
+		compress:  compress,
+		readLimit: readLimit,
+		readDone:  make(chan struct{}),
+	}
+}
+
+// Subprotocol returns the subprotocol negotiated during handshake, see [Options.Subprotocols]
+func (c *Conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// NetConn returns the underlying network connection
+func (c *Conn) NetConn() net.Conn {
+	return c.conn
+}
+
+// SetReadDeadline sets deadline for reads, a zero value means no deadline
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets deadline for writes, a zero value means no deadline
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+// SetPongHandler sets fn to be called (from the reading goroutine) with payload of every pong frame
+func (c *Conn) SetPongHandler(fn func(data []byte)) {
+	c.pongHandler = fn
+}
+
+type frame struct {
+	fin     bool
+	rsv1    bool
+	opcode  byte
+	payload []byte
+}
+
+func (c *Conn) readFrame() (frame, error) {
+	var f frame
+
+	var hdr [2]byte
+	if _, err := io.ReadFull(c.br, hdr[:]); err != nil {
+		return f, err
+	}
+
+	f.fin = hdr[0]&0x80 != 0
+	f.rsv1 = hdr[0]&0x40 != 0
+	f.opcode = hdr[0] & 0x0f
+	masked := hdr[1]&0x80 != 0
+
+	if hdr[0]&0x30 != 0 {
+		return f, &CloseError{Code: StatusProtocolError, Reason: "reserved bits must not be set"}
+	}
+	if masked != c.server {
+		return f, &CloseError{Code: StatusProtocolError, Reason: "invalid frame masking"}
+	}
+
+	length := uint64(hdr[1] & 0x7f)
+	switch length {
+	case 126:
+		var b [2]byte
+		if _, err := io.ReadFull(c.br, b[:]); err != nil {
+			return f, err
+		}
+		length = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err := io.ReadFull(c.br, b[:]); err != nil {
+			return f, err
+		}
+		length = binary.BigEndian.Uint64(b[:])
+	}
+
+	switch f.opcode {
+	case opClose, opPing, opPong:
+		if !f.fin || length > 125 {
+			return f, &CloseError{Code: StatusProtocolError, Reason: "invalid control frame"}
+		}
+		if f.rsv1 {
+			return f, &CloseError{Code: StatusProtocolError, Reason: "reserved bits must not be set"}
+		}
+	case opContinuation, opText, opBinary:
+		if f.rsv1 && (!c.compress || f.opcode == opContinuation) {
+			return f, &CloseError{Code: StatusProtocolError, Reason: "reserved bits must not be set"}
+		}
+		if length > uint64(c.readLimit) {
+			return f, errMessageTooBig
+		}
+	default:
+		return f, &CloseError{Code: StatusProtocolError, Reason: fmt.Sprintf("unknown opcode %d", f.opcode)}
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+			return f, err
+		}
+	}
+
+	f.payload = make([]byte, length)
+	if _, err := io.ReadFull(c.br, f.payload); err != nil {
+		return f, err
+	}
+	if masked {
+		maskBytes(mask, f.payload)
+	}
+
+	return f, nil
+}
+
+func maskBytes(mask [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= mask[i%4]
+	}
+}
+
+// ReadMessage reads the next data message, assembling its fragments, and answering control frames received meanwhile.
+// Once the connection is closed, it returns a [CloseError] (or the network error), and so do all the later calls
+func (c *Conn) ReadMessage() (MessageType, []byte, error) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+
+	return c.readMessage()
+}
+
+func (c *Conn) readMessage() (MessageType, []byte, error) {
+	if c.readErr != nil {
+		return 0, nil, c.readErr
+	}
+
+	typ, msg, err := c.nextMessage()
+	if err != nil {
+		var ce *CloseError
+		if errors.As(err, &ce) && ce != c.readErr {
+			// INFO: it is a protocol violation by the peer, that must be answered with a close frame
+			c.writeClose(ce.Code, ce.Reason)
+		}
+		c.failRead(err)
+		return 0, nil, err
+	}
+
+	return typ, msg, nil
+}
+
+func (c *Conn) nextMessage() (MessageType, []byte, error) {
+	var typ MessageType
+	var compressed bool
+	var msg []byte
+	started := false
+
+	for {
+		f, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch f.opcode {
+		case opClose, opPing, opPong:
+			if err := c.handleControl(f); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opContinuation:
+			if !started {
+				return 0, nil, &CloseError{Code: StatusProtocolError, Reason: "unexpected continuation frame"}
+			}
+		default:
+			if started {
+				return 0, nil, &CloseError{Code: StatusProtocolError, Reason: "expected continuation frame"}
+			}
+			started = true
+			typ = MessageType(f.opcode)
+			compressed = f.rsv1
+		}
+
+		if int64(len(msg)+len(f.payload)) > c.readLimit {
+			return 0, nil, errMessageTooBig
+		}
+		msg = append(msg, f.payload...)
+
+		if f.fin {
+			break
+		}
+	}
+
+	if compressed {
+		var err error
+		if msg, err = decompress(msg, c.readLimit); err != nil {
+			if err == errMessageTooBig {
+				return 0, nil, err
+			}
+			return 0, nil, &CloseError{Code: StatusProtocolError, Reason: "invalid compressed message"}
+		}
+	}
+
+	if typ == TextMessage && !utf8.Valid(msg) {
+		return 0, nil, &CloseError{Code: StatusInvalidPayload, Reason: "invalid utf-8 in text message"}
+	}
+
+	if msg == nil {
+		msg = []byte{}
+	}
+	return typ, msg, nil
+}
+
+func (c *Conn) handleControl(f frame) error {
+	switch f.opcode {
+	case opPing:
+		if err := c.writeFrame(opPong, true, false, f.payload); err != nil && err != ErrClosed {
+			return err
+		}
+	case opPong:
+		if c.pongHandler != nil {
+			c.pongHandler(f.payload)
+		}
+	case opClose:
+		ce := &CloseError{Code: StatusNoStatusReceived}
+		switch {
+		case len(f.payload) == 1:
+			return &CloseError{Code: StatusProtocolError, Reason: "invalid close frame"}
+		case len(f.payload) >= 2:
+			ce.Code = StatusCode(binary.BigEndian.Uint16(f.payload))
+			ce.Reason = string(f.payload[2:])
+			if !validCloseCode(ce.Code) {
+				return &CloseError{Code: StatusProtocolError, Reason: "invalid close code"}
+			}
+			if !utf8.ValidString(ce.Reason) {
+				return &CloseError{Code: StatusInvalidPayload, Reason: "invalid utf-8 in close reason"}
+			}
+		}
+
+		// INFO: close frame is echoed with the same status code, if we have not sent one yet
+		if ce.Code == StatusNoStatusReceived {
+			c.writeFrame(opClose, true, false, nil)
+		} else {
+			c.writeClose(ce.Code, "")
+		}
+
+		// INFO: peer's close frame is not a violation, readMessage must not answer it again
+		c.readErr = ce
+		if c.server {
+			// server closes the TCP connection first, once close frames have been exchanged
+			c.closeConn()
+		}
+		return ce
+	}
+	return nil
+}
+
+func validCloseCode(code StatusCode) bool {
+	switch {
+	case code >= 1000 && code <= 1014:
+		return code != 1004 && code != StatusNoStatusReceived && code != StatusAbnormalClosure
+	case code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
+
+func (c *Conn) failRead(err error) {
+	c.readErr = err
+	select {
+	case <-c.readDone:
+	default:
+		close(c.readDone)
+	}
+}
+
+func (c *Conn) writeFrame(opcode byte, fin bool, rsv1 bool, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closeSent {
+		return ErrClosed
+	}
+	if opcode == opClose {
+		c.closeSent = true
+	}
+
+	b0 := opcode
+	if fin {
+		b0 |= 0x80
+	}
+	if rsv1 {
+		b0 |= 0x40
+	}
+
+	var b1 byte
+	if !c.server {
+		b1 = 0x80
+	}
+
+	hdr := make([]byte, 0, 14)
+	hdr = append(hdr, b0)
+	switch n := len(payload); {
+	case n <= 125:
+		hdr = append(hdr, b1|byte(n))
+	case n <= 0xffff:
+		hdr = append(hdr, b1|126)
+		hdr = binary.BigEndian.AppendUint16(hdr, uint16(n))
+	default:
+		hdr = append(hdr, b1|127)
+		hdr = binary.BigEndian.AppendUint64(hdr, uint64(n))
+	}
+
+	if !c.server {
+		var mask [4]byte
+		rand.Read(mask[:])
+		hdr = append(hdr, mask[:]...)
+
+		// INFO: payload belongs to the caller, so it is masked in a copy
+		payload = append([]byte(nil), payload...)
+		maskBytes(mask, payload)
+	}
+
+	if _, err := c.bw.Write(hdr); err != nil {
+		return err
+	}
+	if _, err := c.bw.Write(payload); err != nil {
+		return err
+	}
+	return c.bw.Flush()
+}
+
+func (c *Conn) writeClose(code StatusCode, reason string) error {
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	// INFO: control frames can carry 125 bytes at most
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	return c.writeFrame(opClose, true, false, append(payload, reason...))
+}
+
+// WriteMessage sends data as a single message, it is fragmented when bigger than 32KB
+func (c *Conn) WriteMessage(typ MessageType, data []byte) error {
+	w, err := c.NextWriter(typ)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// NextWriter returns a writer for the next message, whose writes are sent as fragments of the message.
+// Message is finished by closing the writer, and no other message can be written until then
+func (c *Conn) NextWriter(typ MessageType) (io.WriteCloser, error) {
+	if typ != TextMessage && typ != BinaryMessage {
+		return nil, fmt.Errorf("websocket: invalid message type %d", typ)
+	}
+
+	c.msgMu.Lock()
+	w := &messageWriter{c: c, opcode: byte(typ)}
+	if c.compress {
+		w.fw = flateWriters.Get().(*flate.Writer)
+		w.fw.Reset(&w.buf)
+	}
+	return w, nil
+}
+
+type messageWriter struct {
+	c      *Conn
+	opcode byte
+	buf    bytes.Buffer
+	fw     *flate.Writer
+
+	// sent is set, once the first fragment has gone out, others are continuation frames
+	sent   bool
+	closed bool
+	err    error
+}
+
+func (w *messageWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrClosed
+	}
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	if w.fw != nil {
+		if _, err := w.fw.Write(p); err != nil {
+			return 0, err
+		}
+	} else {
+		w.buf.Write(p)
+	}
+
+	for w.buf.Len() > fragmentSize {
+		if w.err = w.writeFragment(w.buf.Next(fragmentSize), false); w.err != nil {
+			return 0, w.err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *messageWriter) writeFragment(b []byte, fin bool) error {
+	opcode := byte(opContinuation)
+	if !w.sent {
+		opcode = w.opcode
+	}
+	// INFO: only first frame of a compressed message has RSV1 set
+	err := w.c.writeFrame(opcode, fin, w.fw != nil && !w.sent, b)
+	w.sent = true
+	return err
+}
+
+func (w *messageWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	defer w.c.msgMu.Unlock()
+
+	if w.fw != nil {
+		defer flateWriters.Put(w.fw)
+		if w.err == nil {
+			w.err = w.fw.Flush()
+		}
+		if w.err == nil {
+			w.buf.Truncate(w.buf.Len() - len(deflateTail))
+		}
+	}
+
+	if w.err != nil {
+		return w.err
+	}
+	return w.writeFragment(w.buf.Bytes(), true)
+}
+
+// Ping sends a ping frame, peer's pong is passed to the handler set with [Conn.SetPongHandler]
+func (c *Conn) Ping(data []byte) error {
+	if len(data) > 125 {
+		return errors.New("websocket: ping payload must not be bigger than 125 bytes")
+	}
+	return c.writeFrame(opPing, true, false, data)
+}
+
+// Close closes the connection with [StatusNormalClosure]
+func (c *Conn) Close() error {
+	return c.CloseWithStatus(StatusNormalClosure, "")
+}
+
+// CloseWithStatus performs the close handshake, i.e. sends a close frame with code and reason,
+// and waits (for up to 5 seconds) for the peer's close frame, before closing the network connection.
+//
+// If no other goroutine is reading from the connection, messages received meanwhile are discarded.
+func (c *Conn) CloseWithStatus(code StatusCode, reason string) error {
+	defer c.closeConn()
+
+	err := c.writeClose(code, reason)
+	if err == ErrClosed {
+		err = nil
+	}
+
+	if c.readMu.TryLock() {
+		c.conn.SetReadDeadline(time.Now().Add(closeTimeout))
+		for c.readErr == nil {
+			c.readMessage()
+		}
+		c.readMu.Unlock()
+		return err
+	}
+
+	select {
+	case <-c.readDone:
+	case <-time.After(closeTimeout):
+	}
+	return err
+}
+
+func (c *Conn) closeConn() {
+	c.closeOnce.Do(func() {
+		c.conn.Close()
+	})
+}
@@ -0,0 +1,75 @@
+package ws
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// deflateTail is the end of a sync flush, which permessage-deflate strips from compressed messages
+var deflateTail = []byte{0x00, 0x00, 0xff, 0xff}
+
+// negotiateDeflate accepts the first permessage-deflate offer, that can be served, and returns the extension response.
+//
+// INFO: contexts are never taken over, in either direction, so that neither side keeps a compressor around for idle connections
+func negotiateDeflate(h http.Header) (string, bool) {
+	for _, offer := range headerTokens(h, "Sec-WebSocket-Extensions") {
+		params := strings.Split(offer, ";")
+		if strings.TrimSpace(params[0]) != "permessage-deflate" {
+			continue
+		}
+
+		ok := true
+		for _, p := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(p), "=")
+			switch strings.TrimSpace(name) {
+			case "server_no_context_takeover", "client_no_context_takeover", "client_max_window_bits":
+			case "server_max_window_bits":
+				// compress/flate always uses a 32KB window, i.e. 15 bits
+				ok = ok && strings.Trim(strings.TrimSpace(value), `"`) == "15"
+			default:
+				ok = false
+			}
+		}
+
+		if ok {
+			return "permessage-deflate; server_no_context_takeover; client_no_context_takeover", true
+		}
+	}
+	return "", false
+}
+
+var flateWriters = sync.Pool{
+	New: func() any {
+		fw, _ := flate.NewWriter(nil, flate.BestSpeed)
+		return fw
+	},
+}
+
+var flateReaders = sync.Pool{
+	New: func() any {
+		return flate.NewReader(nil)
+	},
+}
+
+// decompress inflates a permessage-deflate message, failing with errMessageTooBig, when it gets bigger than limit
+func decompress(b []byte, limit int64) ([]byte, error) {
+	fr := flateReaders.Get().(io.ReadCloser)
+	defer flateReaders.Put(fr)
+
+	fr.(flate.Resetter).Reset(io.MultiReader(bytes.NewReader(b), bytes.NewReader(deflateTail)), nil)
+
+	var out bytes.Buffer
+	n, err := io.Copy(&out, io.LimitReader(fr, limit+1))
+	// INFO: stream ends at the sync flush without a final block, so io.ErrUnexpectedEOF is expected there
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	if n > limit {
+		return nil, errMessageTooBig
+	}
+	return out.Bytes(), nil
+}
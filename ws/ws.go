@@ -0,0 +1,209 @@
+// Package ws implements [RFC 6455](https://www.rfc-editor.org/rfc/rfc6455) WebSockets for ivy,
+// with ping/pong, close handshake, fragmentation and optional [permessage-deflate](https://www.rfc-editor.org/rfc/rfc7692) compression.
+//
+// Upgrade happens in an ivy handler, so router's middlewares (auth, request ID, logging etc.) run before it.
+//
+// Example:
+//
+//	r.Use(middleware.RequestID())
+//	r.Get("/chat", ws.Handler(func(c *ivy.Context, conn *ws.Conn) error {
+//	    for {
+//	        typ, msg, err := conn.ReadMessage()
+//	        if err != nil {
+//	            if ws.CloseStatus(err) == ws.StatusNormalClosure {
+//	                return nil
+//	            }
+//	            return err
+//	        }
+//	        if err := conn.WriteMessage(typ, msg); err != nil {
+//	            return err
+//	        }
+//	    }
+//	}))
+package ws
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+// Options configures the upgrade, and the resulting connection
+type Options struct {
+	// Subprotocols supported by the server, in order of preference.
+	// First of them, that client also asks for (with `Sec-WebSocket-Protocol` header) is selected, see [Conn.Subprotocol]
+	Subprotocols []string
+
+	// CheckOrigin decides whether the request's Origin is allowed to connect.
+	// It defaults to allowing requests without an Origin header, or with the same host as the request
+	CheckOrigin func(r *http.Request) bool
+
+	// EnableCompression negotiates permessage-deflate extension, when the client offers it
+	EnableCompression bool
+
+	// ReadLimit is the maximum size of a message (after decompression), that can be read.
+	// Bigger messages close the connection with [StatusMessageTooBig]. It defaults to [DefaultReadLimit]
+	ReadLimit int64
+}
+
+// DefaultReadLimit is the maximum size of a message, when it is not set in Options
+const DefaultReadLimit = 32 << 20
+
+// websocketGUID is appended to Sec-WebSocket-Key, to compute Sec-WebSocket-Accept
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Handler upgrades the request to a WebSocket connection, and hands it over to fn.
+//
+// Connection is closed once fn returns, with [StatusNormalClosure] if fn returned nil, and [StatusInternalError] otherwise.
+// Errors returned by fn are passed to router's ErrorHandler, which only logs them, as the response has already been committed.
+func Handler(fn func(c *ivy.Context, conn *Conn) error, opts ...Options) ivy.Handler {
+	return func(c *ivy.Context) error {
+		conn, err := Upgrade(c, opts...)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(c, conn); err != nil {
+			conn.CloseWithStatus(StatusInternalError, "")
+			return err
+		}
+
+		return conn.Close()
+	}
+}
+
+// Upgrade performs the opening handshake, and returns the WebSocket connection.
+// It fails with an [ivy.HTTPError], when the request is not a valid WebSocket handshake.
+// Headers already set on the response (for example, cookies) are sent along with the handshake.
+func Upgrade(c *ivy.Context, opts ...Options) (*Conn, error) {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.CheckOrigin == nil {
+		opt.CheckOrigin = sameOrigin
+	}
+	if opt.ReadLimit <= 0 {
+		opt.ReadLimit = DefaultReadLimit
+	}
+
+	r := c.Request()
+
+	if r.Method != http.MethodGet {
+		return nil, ivy.NewHTTPError(http.StatusMethodNotAllowed, "websocket: handshake must be a GET request")
+	}
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		return nil, ivy.NewHTTPError(http.StatusBadRequest, "websocket: missing upgrade headers")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		c.SetHeader("Sec-WebSocket-Version", "13")
+		return nil, ivy.NewHTTPError(http.StatusUpgradeRequired, "websocket: unsupported version")
+	}
+
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if b, err := base64.StdEncoding.DecodeString(key); err != nil || len(b) != 16 {
+		return nil, ivy.NewHTTPError(http.StatusBadRequest, "websocket: invalid Sec-WebSocket-Key")
+	}
+	if !opt.CheckOrigin(r) {
+		return nil, ivy.NewHTTPError(http.StatusForbidden, "websocket: origin not allowed")
+	}
+
+	h := c.ResponseWriter().Header()
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	h.Set("Upgrade", "websocket")
+	h.Set("Connection", "Upgrade")
+	h.Set("Sec-WebSocket-Accept", acceptKey(key))
+
+	subprotocol := selectSubprotocol(r, opt.Subprotocols)
+	if subprotocol != "" {
+		h.Set("Sec-WebSocket-Protocol", subprotocol)
+	}
+
+	compress := false
+	if opt.EnableCompression {
+		if ext, ok := negotiateDeflate(r.Header); ok {
+			compress = true
+			h.Set("Sec-WebSocket-Extensions", ext)
+		}
+	}
+
+	netConn, brw, err := http.NewResponseController(c.ResponseWriter()).Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("websocket: %w", err)
+	}
+
+	// INFO: hijacked connections keep deadlines, that http.Server has set for reading the request, and writing the response
+	netConn.SetDeadline(time.Time{})
+
+	brw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	h.Write(brw)
+	brw.WriteString("\r\n")
+	if err := brw.Flush(); err != nil {
+		netConn.Close()
+		return nil, fmt.Errorf("websocket: %w", err)
+	}
+
+	conn := newConn(netConn, brw, true, compress, opt.ReadLimit)
+	conn.subprotocol = subprotocol
+	return conn, nil
+}
+
+// acceptKey computes Sec-WebSocket-Accept for the client's Sec-WebSocket-Key
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+func selectSubprotocol(r *http.Request, supported []string) string {
+	requested := headerTokens(r.Header, "Sec-WebSocket-Protocol")
+	for _, s := range supported {
+		for _, p := range requested {
+			if s == p {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// headerTokens splits comma separated values of header key
+func headerTokens(h http.Header, key string) []string {
+	var tokens []string
+	for _, v := range h.Values(key) {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				tokens = append(tokens, t)
+			}
+		}
+	}
+	return tokens
+}
+
+func headerContains(h http.Header, key, token string) bool {
+	for _, t := range headerTokens(h, key) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+package ws
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nxtcoder17/ivy"
+)
+
+// dial performs the opening handshake with srv, and returns the client side of the connection
+func dial(t *testing.T, srv *httptest.Server, path string, headers map[string]string) (*Conn, *http.Response) {
+	t.Helper()
+
+	netConn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { netConn.Close() })
+	netConn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if err := req.Write(netConn); err != nil {
+		t.Fatal(err)
+	}
+
+	brw := bufio.NewReadWriter(bufio.NewReader(netConn), bufio.NewWriter(netConn))
+	resp, err := http.ReadResponse(brw.Reader, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compress := strings.HasPrefix(resp.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate")
+	return newConn(netConn, brw, false, compress, DefaultReadLimit), resp
+}
+
+func TestAcceptKey(t *testing.T) {
+	// example from RFC 6455, section 1.3
+	if got := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestNegotiateDeflate(t *testing.T) {
+	tests := []struct {
+		name       string
+		extensions string
+		want       bool
+	}{
+		{name: "1. plain offer", extensions: "permessage-deflate", want: true},
+		{name: "2. 15 bits server window", extensions: `permessage-deflate; server_max_window_bits="15"`, want: true},
+		{name: "3. smaller server window", extensions: "permessage-deflate; server_max_window_bits=10", want: false},
+		{name: "4. unknown param before server window", extensions: "permessage-deflate; unknown_param; server_max_window_bits=15", want: false},
+		{name: "5. falls back to next offer", extensions: "permessage-deflate; unknown_param, permessage-deflate; client_max_window_bits", want: true},
+		{name: "6. other extension", extensions: "x-webkit-deflate-frame", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			h.Set("Sec-WebSocket-Extensions", tt.extensions)
+			if _, got := negotiateDeflate(h); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	closed := make(chan error, 1)
+
+	r := ivy.NewRouter()
+	r.Use(func(c *ivy.Context) error {
+		if c.QueryParam("token") != "secret" {
+			return ivy.NewHTTPError(http.StatusUnauthorized, "unauthorized")
+		}
+		c.SetHeader("X-Session", "1")
+		return c.Next()
+	})
+	r.Get("/echo", Handler(func(c *ivy.Context, conn *Conn) error {
+		for {
+			typ, msg, err := conn.ReadMessage()
+			if err != nil {
+				closed <- err
+				return nil
+			}
+			if err := conn.WriteMessage(typ, msg); err != nil {
+				return err
+			}
+		}
+	}, Options{Subprotocols: []string{"chat.v2", "chat.v1"}, EnableCompression: true}))
+
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	t.Run("middlewares run before upgrade", func(t *testing.T) {
+		_, resp := dial(t, srv, "/echo", nil)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("status code: got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("invalid handshake", func(t *testing.T) {
+		_, resp := dial(t, srv, "/echo?token=secret", map[string]string{"Sec-WebSocket-Version": "8"})
+		if resp.StatusCode != http.StatusUpgradeRequired || resp.Header.Get("Sec-WebSocket-Version") != "13" {
+			t.Errorf("status code: got %d", resp.StatusCode)
+		}
+
+		_, resp = dial(t, srv, "/echo?token=secret", map[string]string{"Origin": "https://evil.example.com"})
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("status code: got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("messages, fragments, ping and close", func(t *testing.T) {
+		conn, resp := dial(t, srv, "/echo?token=secret", map[string]string{"Sec-WebSocket-Protocol": "chat.v1, chat.v2"})
+		if resp.StatusCode != http.StatusSwitchingProtocols {
+			t.Fatalf("status code: got %d", resp.StatusCode)
+		}
+		if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+			t.Errorf("accept key: got %q", got)
+		}
+		if resp.Header.Get("Sec-WebSocket-Protocol") != "chat.v2" || resp.Header.Get("X-Session") != "1" {
+			t.Errorf("headers: got %v", resp.Header)
+		}
+
+		if err := conn.WriteMessage(TextMessage, []byte("hello")); err != nil {
+			t.Fatal(err)
+		}
+		if typ, msg, err := conn.ReadMessage(); err != nil || typ != TextMessage || string(msg) != "hello" {
+			t.Errorf("echo: got %d %q %v", typ, msg, err)
+		}
+
+		// a fragmented message, with a ping in between its fragments
+		pong := make(chan string, 1)
+		conn.SetPongHandler(func(data []byte) { pong <- string(data) })
+		conn.writeFrame(opBinary, false, false, []byte("frag"))
+		conn.Ping([]byte("are you there"))
+		conn.writeFrame(opContinuation, true, false, []byte("ments"))
+
+		if typ, msg, err := conn.ReadMessage(); err != nil || typ != BinaryMessage || string(msg) != "fragments" {
+			t.Errorf("fragmented echo: got %d %q %v", typ, msg, err)
+		}
+		if got := <-pong; got != "are you there" {
+			t.Errorf("pong: got %q", got)
+		}
+
+		big := bytes.Repeat([]byte("x"), 3*fragmentSize)
+		conn.WriteMessage(BinaryMessage, big)
+		if _, msg, err := conn.ReadMessage(); err != nil || !bytes.Equal(msg, big) {
+			t.Errorf("big message: got %d bytes, %v", len(msg), err)
+		}
+
+		if err := conn.CloseWithStatus(StatusGoingAway, "bye"); err != nil {
+			t.Fatal(err)
+		}
+		if CloseStatus(conn.readErr) != StatusGoingAway {
+			t.Errorf("close reply: got %v", conn.readErr)
+		}
+
+		err := <-closed
+		var ce *CloseError
+		if !errors.As(err, &ce) || ce.Code != StatusGoingAway || ce.Reason != "bye" {
+			t.Errorf("server read: got %v", err)
+		}
+	})
+
+	t.Run("permessage-deflate", func(t *testing.T) {
+		conn, resp := dial(t, srv, "/echo?token=secret", map[string]string{"Sec-WebSocket-Extensions": "permessage-deflate; client_max_window_bits"})
+		if got := resp.Header.Get("Sec-WebSocket-Extensions"); got != "permessage-deflate; server_no_context_takeover; client_no_context_takeover" {
+			t.Fatalf("extensions: got %q", got)
+		}
+
+		msg := strings.Repeat("compress me, ", 1000)
+		if err := conn.WriteMessage(TextMessage, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+
+		f, err := conn.readFrame()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !f.rsv1 || !f.fin || len(f.payload) >= len(msg) {
+			t.Errorf("expected a compressed frame, got rsv1=%v with %d bytes", f.rsv1, len(f.payload))
+		}
+		if got, err := decompress(f.payload, DefaultReadLimit); err != nil || string(got) != msg {
+			t.Errorf("decompressed: got %d bytes, %v", len(got), err)
+		}
+
+		conn.Close()
+		<-closed
+	})
+
+	t.Run("protocol violation", func(t *testing.T) {
+		conn, _ := dial(t, srv, "/echo?token=secret", nil)
+
+		// clients must mask their frames, pretending to be the server sends an unmasked one
+		conn.server = true
+		conn.writeFrame(opText, true, false, []byte("unmasked"))
+		conn.server = false
+
+		if err := <-closed; CloseStatus(err) != StatusProtocolError {
+			t.Errorf("server read: got %v", err)
+		}
+		if _, _, err := conn.ReadMessage(); CloseStatus(err) != StatusProtocolError {
+			t.Errorf("client read: got %v", err)
+		}
+	})
+}